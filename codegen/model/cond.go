@@ -0,0 +1,240 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+// Condition is a subschema applied to the object that holds it, as used by dependentSchemas and
+// if/then/else. Only presence of properties and const/enum constraints on their values are
+// supported, which covers the usual "field X is required when field Y has value Z" rules.
+type Condition struct {
+	// Required lists properties that must be present.
+	Required []string
+	// Props restricts the values of properties, if present.
+	Props []PropCondition
+}
+
+// PropCondition restricts a property to one of Values, which hold the decoded const or enum values
+// (string, int64, float64, bool or nil).
+type PropCondition struct {
+	Name   string
+	Values []any
+}
+
+// DependentRequired lists properties that must be present when Prop is present.
+type DependentRequired struct {
+	Prop     string
+	Required []string
+}
+
+// DependentSchema is a condition that the object must satisfy when Prop is present.
+type DependentSchema struct {
+	Prop string
+	Cond *Condition
+}
+
+// hasConditionals reports whether a schema uses any of the keywords that produce conditions.
+func hasConditionals(schema *base.Schema) bool {
+	return orderedmap.Len(schema.DependentRequired) > 0 || orderedmap.Len(schema.DependentSchemas) > 0 ||
+		schema.If != nil || schema.Then != nil || schema.Else != nil
+}
+
+// visitConditionals collects dependentRequired, dependentSchemas and if/then/else on an object schema.
+func (r *Registry) visitConditionals(l Location, schema *base.Schema, typ *Type) error {
+	if !hasConditionals(schema) {
+		return nil
+	}
+
+	for pair := schema.DependentRequired.First(); pair != nil; pair = pair.Next() {
+		typ.DependentRequired = append(typ.DependentRequired, DependentRequired{
+			Prop:     pair.Key(),
+			Required: pair.Value(),
+		})
+	}
+
+	for pair := schema.DependentSchemas.First(); pair != nil; pair = pair.Next() {
+		cond, err := makeCondition(l, schema, "dependentSchemas/"+escapeSegment(pair.Key()), pair.Value())
+		if err != nil {
+			return err
+		}
+
+		typ.DependentSchemas = append(typ.DependentSchemas, DependentSchema{
+			Prop: pair.Key(),
+			Cond: cond,
+		})
+	}
+
+	if schema.If == nil {
+		if schema.Then != nil || schema.Else != nil {
+			return fmt.Errorf("schema %s has then or else without if", l)
+		}
+
+		return nil
+	}
+
+	var err error
+	if typ.If, err = makeCondition(l, schema, "if", schema.If); err != nil {
+		return err
+	}
+
+	if schema.Then != nil {
+		if typ.Then, err = makeCondition(l, schema, "then", schema.Then); err != nil {
+			return err
+		}
+	}
+
+	if schema.Else != nil {
+		if typ.Else, err = makeCondition(l, schema, "else", schema.Else); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func makeCondition(l Location, parent *base.Schema, keyword string, sp *base.SchemaProxy) (*Condition, error) {
+	if sp.IsReference() {
+		return nil, fmt.Errorf("schema %s/%s must not be a reference", l, keyword)
+	}
+
+	schema := sp.Schema()
+	if err := checkConditionSchema(schema, true); err != nil {
+		return nil, fmt.Errorf("schema %s/%s: %w", l, keyword, err)
+	}
+
+	cond := &Condition{
+		Required: slices.Clone(schema.Required),
+	}
+
+	for prop := schema.Properties.First(); prop != nil; prop = prop.Next() {
+		if prop.Value().IsReference() {
+			return nil, fmt.Errorf("schema %s/%s/properties/%s must not be a reference", l, keyword, prop.Key())
+		}
+
+		propSchema := prop.Value().Schema()
+		if err := checkConditionSchema(propSchema, false); err != nil {
+			return nil, fmt.Errorf("schema %s/%s/properties/%s: %w", l, keyword, prop.Key(), err)
+		}
+
+		nodes := propSchema.Enum
+		if propSchema.Const != nil {
+			nodes = []*yaml.Node{propSchema.Const}
+		}
+
+		if len(nodes) == 0 {
+			continue
+		}
+
+		target, err := conditionTarget(parent, prop.Key())
+		if err != nil {
+			return nil, fmt.Errorf("schema %s/%s/properties/%s: %w", l, keyword, prop.Key(), err)
+		}
+
+		pc := PropCondition{Name: prop.Key()}
+		for i, n := range nodes {
+			v, err := decodeScalar(n)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s/%s/properties/%s value %d: %w", l, keyword, prop.Key(), i, err)
+			}
+
+			// Generators compare the values with the property, so they must be literals of its Go type
+			if !fitsType(v, target) {
+				return nil, fmt.Errorf("schema %s/%s/properties/%s value %d does not match the type of the property", l, keyword, prop.Key(), i)
+			}
+
+			pc.Values = append(pc.Values, v)
+		}
+
+		cond.Props = append(cond.Props, pc)
+	}
+
+	return cond, nil
+}
+
+// checkConditionSchema rejects keywords that conditions cannot express, so that they are not
+// silently dropped. Object level keywords are allowed only at the top of the condition.
+func checkConditionSchema(schema *base.Schema, top bool) error {
+	if len(schema.AllOf) > 0 || len(schema.AnyOf) > 0 || len(schema.OneOf) > 0 || schema.Not != nil ||
+		hasConditionals(schema) {
+		return fmt.Errorf("composition and conditional keywords are not supported in conditions")
+	}
+
+	if schema.Minimum != nil || schema.Maximum != nil || schema.ExclusiveMinimum != nil || schema.ExclusiveMaximum != nil ||
+		schema.MultipleOf != nil || schema.MinLength != nil || schema.MaxLength != nil || schema.Pattern != "" ||
+		schema.MinItems != nil || schema.MaxItems != nil || schema.Items != nil || len(schema.PrefixItems) > 0 ||
+		schema.MinProperties != nil || schema.MaxProperties != nil || schema.AdditionalProperties != nil ||
+		orderedmap.Len(schema.PatternProperties) > 0 || schema.PropertyNames != nil {
+		return fmt.Errorf("only required, const and enum are supported in conditions")
+	}
+
+	if !top && (orderedmap.Len(schema.Properties) > 0 || len(schema.Required) > 0) {
+		return fmt.Errorf("nested properties are not supported in conditions")
+	}
+
+	return nil
+}
+
+// conditionTarget ensures that a property whose value is restricted by a condition is declared on
+// the parent object with a scalar type that can be compared with const and enum values. It returns
+// the type the property is generated with.
+func conditionTarget(parent *base.Schema, name string) (*Type, error) {
+	sp, ok := parent.Properties.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("const and enum conditions are only supported on declared properties")
+	}
+
+	schema := sp.Schema()
+	st, nullable := normalizeSchemaType(schema)
+	if len(st) != 1 {
+		return nil, fmt.Errorf("const and enum conditions are only supported on properties with a single type")
+	}
+
+	typ := &Type{Nullable: nullable}
+	switch st[0] {
+	case "string":
+		switch schema.Format {
+		case "date", "date-time", "binary", "byte":
+			return nil, fmt.Errorf("const and enum conditions are not supported on %s strings", schema.Format)
+		}
+		typ.Kind = TypeString
+	case "integer":
+		typ.Kind = TypeInt64
+		if schema.Format == "int32" {
+			typ.Kind = TypeInt32
+		}
+	case "number":
+		typ.Kind = TypeFloat64
+	case "boolean":
+		typ.Kind = TypeBool
+	default:
+		return nil, fmt.Errorf("const and enum conditions are only supported on scalar properties")
+	}
+
+	return typ, nil
+}
+
+// decodeScalar decodes a const or enum value used in a condition or a contains schema.
+func decodeScalar(n *yaml.Node) (any, error) {
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	switch x := v.(type) {
+	case nil, string, bool, float64:
+		return x, nil
+	case int:
+		return int64(x), nil
+	case int64:
+		return x, nil
+	case uint64:
+		return nil, fmt.Errorf("integer %d is out of range", x)
+	}
+
+	return nil, fmt.Errorf("only scalar values are supported")
+}
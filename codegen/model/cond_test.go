@@ -0,0 +1,78 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConditionValues(t *testing.T) {
+	tests := []struct {
+		name string
+		prop string
+		cond string
+		want []any
+	}{
+		{"string", "{type: string}", "{const: active}", []any{"active"}},
+		{"integer", "{type: integer}", "{enum: [1, 2]}", []any{int64(1), int64(2)}},
+		{"integral number for an integer", "{type: integer}", "{const: 2.0}", []any{2.0}},
+		{"integer for a number", "{type: number}", "{const: 2}", []any{int64(2)}},
+		{"null for a nullable property", "{type: [boolean, 'null']}", "{enum: [true, null]}", []any{true, nil}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := collect(t, `
+    Obj:
+      type: object
+      properties:
+        kind: `+test.prop+`
+      if:
+        properties:
+          kind: `+test.cond+`
+      then:
+        required: [kind]
+`)
+			obj, _ := r.Get("Obj")
+			if got := obj.Type.If.Props[0].Values; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConditionValueErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		prop string
+		cond string
+		want string
+	}{
+		{"number for a string", "{type: string}", "{kind: {const: 1}}", "schema Obj/if/properties/kind value 0 does not match the type of the property"},
+		{"string for an integer", "{type: integer}", "{kind: {enum: [1, '2']}}", "schema Obj/if/properties/kind value 1 does not match the type of the property"},
+		{"fraction for an integer", "{type: integer}", "{kind: {const: 1.5}}", "schema Obj/if/properties/kind value 0 does not match"},
+		{"out of range for int32", "{type: integer, format: int32}", "{kind: {const: 3000000000}}", "schema Obj/if/properties/kind value 0 does not match"},
+		{"null for a property that is not nullable", "{type: boolean}", "{kind: {const: null}}", "schema Obj/if/properties/kind value 0 does not match"},
+		{"undeclared property", "{type: string}", "{other: {const: a}}", "only supported on declared properties"},
+		{"date string", "{type: string, format: date}", "{kind: {const: '2024-01-01'}}", "not supported on date strings"},
+		{"array property", "{type: array}", "{kind: {const: 1}}", "only supported on scalar properties"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := tryCollect(`
+    Obj:
+      type: object
+      properties:
+        kind: ` + test.prop + `
+      if:
+        properties: ` + test.cond + `
+      then:
+        required: [kind]
+`)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package model
 import (
 	"fmt"
 	"math"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/maketaio/openapi/internal/util/ptr"
@@ -11,6 +13,7 @@ import (
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
 )
 
 type TypeKind int
@@ -24,26 +27,40 @@ const (
 	TypeString
 	TypeObject
 	TypeArray
+	TypeTuple
 	TypeRef
 )
 
 type Type struct {
-	Kind     TypeKind
-	Enum     []EnumConst // For simple kinds where enums can be defined
-	Fields   []Field     // For struct kind
-	Elem     *Type       // For slice, map and struct kind
-	Ref      string      // For reference kind, the ID of the declaration being referenced
-	Nullable bool
+	Kind         TypeKind
+	Enum         []EnumConst   // For simple kinds where enums can be defined
+	Fields       []Field       // For struct and tuple kind, tuple fields are named after their index
+	Elem         *Type         // For slice, map, struct and tuple kind
+	PatternProps []PatternProp // For map and struct kind, properties whose names match a pattern
+	PropNames    *Type         // For map and struct kind, constraints on property names
+	Ref          string        // For reference kind, the ID of the declaration being referenced
+	Nullable     bool
 
 	// Validation
-	Min, Max         *int64   // For int32, int64, string, map, slice
-	ExclMin, ExclMax bool     // For int32, int64, float64
-	MultipleOf       *int64   // For int32, int64
-	Len              *int64   // For string, map, slice
-	Pattern          string   // For string
-	Format           string   // For string
-	MinF, MaxF       *float64 // For float64
-	NullOnly         bool     // Must be null only
+	Min, Max         *int64    // For int32, int64, string, map, slice, tuple
+	ExclMin, ExclMax bool      // For int32, int64, float64
+	MultipleOf       *int64    // For int32, int64
+	Len              *int64    // For string, map, slice
+	Pattern          string    // For string
+	Format           string    // For string
+	MinF, MaxF       *float64  // For float64
+	UniqueItems      bool      // For slice
+	Contains         *Contains // For slice, the schema that some items must match
+	NullOnly         bool      // Must be null only
+
+	// RejectUnknown is set on struct kinds without extra properties whose undeclared properties must
+	// be rejected while decoding rather than dropped, see AdditionalPropsReject.
+	RejectUnknown bool
+
+	// Conditional validation, for struct kind
+	DependentRequired []DependentRequired
+	DependentSchemas  []DependentSchema
+	If, Then, Else    *Condition
 }
 
 type EnumConst struct {
@@ -58,35 +75,74 @@ type EnumConst struct {
 	Doc     []string
 }
 
+// Contains describes the contains schema of an array along with minContains and maxContains. An item
+// matches the schema when it matches one of Types, or when it is null and Nullable is set.
+type Contains struct {
+	Types    []ContainsType // One per JSON type allowed by the schema
+	Nullable bool
+	Min      int64  // minContains, 1 by default
+	Max      *int64 // maxContains
+}
+
+// ContainsType is a type allowed by a contains schema. Values holds the const or enum values of the
+// schema that fit Type, decoded as in PropCondition; items of Type must equal one of them if set.
+type ContainsType struct {
+	Type   *Type
+	Values []any
+}
+
+// PatternProp describes the values of properties whose names match Pattern.
+type PatternProp struct {
+	Pattern string
+	Type    *Type
+}
+
 type Field struct {
 	Name       string
 	Type       *Type
 	Required   bool
 	Deprecated bool
 	Doc        []string
+
+	// Optional is the representation of the field when it is not required or nullable, set with
+	// x-go-optional. Tuple items always use OptionalWrapper, since their presence matters.
+	Optional OptionalRepr
 }
 
 // Declaration represents a top-level type or class to be generated. An OpenAPI schema becomes
-// a declaration when it is defined at the top level of components.schemas or when it is an enum or a
-// nested object schema.
+// a declaration when it is defined at the top level of components.schemas, when it is an enum or a
+// tuple, or when it is a nested schema hoisted according to the HoistPolicy of the registry.
 type Declaration struct {
 	ID         string
 	Type       *Type
 	Doc        []string
 	Loc        Location
 	Deprecated bool
+	SQLJSON    bool   // Stored in a JSON database column, set with x-go-sql-json
+	Name       string // Name of the generated type, set with x-go-name. Generators pick one if empty.
 }
 
-// Registry collects and stores declarations
+// Registry collects and stores declarations and operations
 type Registry struct {
 	decls map[string]*Declaration
 	ids   []string
+	ops   []*Operation
+
+	additionalProps AdditionalPropsPolicy
+	optional        OptionalRepr
+	hoist           HoistPolicy
 }
 
-func NewRegistry() *Registry {
-	return &Registry{
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
 		decls: map[string]*Declaration{},
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 func (r *Registry) Collect(dm *libopenapi.DocumentModel[v3.Document]) error {
@@ -98,9 +154,38 @@ func (r *Registry) Collect(dm *libopenapi.DocumentModel[v3.Document]) error {
 		}
 	}
 
+	if err := r.collectOperations(dm.Model.Paths); err != nil {
+		return err
+	}
+
+	r.breakValueCycles()
 	return nil
 }
 
+// breakValueCycles represents fields with OptionalPointer rather than OptionalValue when their type
+// refers back to the declaration holding them, since a type cannot contain itself by value.
+func (r *Registry) breakValueCycles() {
+	for _, scc := range r.StronglyConnectedComponents() {
+		for _, id := range scc {
+			breakValueCycle(r.decls[id].Type, scc)
+		}
+	}
+}
+
+func breakValueCycle(typ *Type, scc []string) {
+	for i := range typ.Fields {
+		f := &typ.Fields[i]
+		if f.Optional == OptionalValue && f.Type.Kind == TypeRef && slices.Contains(scc, f.Type.Ref) {
+			f.Optional = OptionalPointer
+		}
+
+		// Inline objects are held by value as well
+		if f.Type.Kind == TypeObject {
+			breakValueCycle(f.Type, scc)
+		}
+	}
+}
+
 func (r *Registry) Get(id string) (*Declaration, bool) {
 	m, ok := r.decls[id]
 	return m, ok
@@ -127,6 +212,9 @@ func (r *Registry) addDecl(l Location, typ *Type, schema *base.Schema) string {
 	if schema != nil {
 		m.Doc = toDocLines(schema.Description)
 		m.Deprecated = ptr.Deref(schema.Deprecated, false)
+		// Checked by visit already.
+		m.SQLJSON, _ = extBool(schema, "x-go-sql-json")
+		m.Name, _ = extString(schema, "x-go-name")
 	}
 
 	r.decls[m.ID] = m
@@ -176,26 +264,24 @@ func (r *Registry) visit(l Location, sp *base.SchemaProxy) (*Type, error) {
 		return nil, fmt.Errorf("schema %s has multiple types, which is not supported at the moment", l)
 	}
 
-	var typ *Type
-	var err error
+	sqlJSON, err := extBool(schema, "x-go-sql-json")
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", l, err)
+	}
 
-	switch st[0] {
-	case "string":
-		typ, err = r.visitStr(l, schema)
-	case "integer":
-		typ, err = r.visitInt(l, schema)
-	case "number":
-		typ, err = r.visitNum(l, schema)
-	case "boolean":
-		typ, err = r.visitBool(l, schema)
-	case "array":
-		typ, err = r.visitArr(l, schema)
-	case "object":
-		typ, err = r.visitObj(l, schema)
-	default:
-		return nil, fmt.Errorf("unhandled type %s for %s", st[0], l)
+	if sqlJSON && st[0] != "object" {
+		return nil, fmt.Errorf("schema %s sets x-go-sql-json, which is only supported on objects", l)
 	}
 
+	if _, err := extString(schema, "x-go-name"); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", l, err)
+	}
+
+	if st[0] != "object" && hasConditionals(schema) {
+		return nil, fmt.Errorf("schema %s uses dependentRequired, dependentSchemas or if/then/else, which are only supported on objects", l)
+	}
+
+	typ, err := r.visitType(l, st[0], schema)
 	if err != nil {
 		return nil, err
 	}
@@ -207,6 +293,26 @@ func (r *Registry) visit(l Location, sp *base.SchemaProxy) (*Type, error) {
 	return typ, nil
 }
 
+// visitType converts a schema into a Type of the given JSON schema type.
+func (r *Registry) visitType(l Location, t string, schema *base.Schema) (*Type, error) {
+	switch t {
+	case "string":
+		return r.visitStr(l, schema)
+	case "integer":
+		return r.visitInt(l, schema)
+	case "number":
+		return r.visitNum(l, schema)
+	case "boolean":
+		return r.visitBool(l, schema)
+	case "array":
+		return r.visitArr(l, schema)
+	case "object":
+		return r.visitObj(l, schema)
+	}
+
+	return nil, fmt.Errorf("unhandled type %s for %s", t, l)
+}
+
 func (r *Registry) visitStr(l Location, schema *base.Schema) (*Type, error) {
 	typ := &Type{
 		Kind:    TypeString,
@@ -349,6 +455,10 @@ func (r *Registry) visitBool(l Location, schema *base.Schema) (*Type, error) {
 }
 
 func (r *Registry) visitArr(l Location, schema *base.Schema) (*Type, error) {
+	if len(schema.PrefixItems) > 0 {
+		return r.visitTuple(l, schema)
+	}
+
 	typ := &Type{
 		Kind: TypeArray,
 	}
@@ -360,6 +470,8 @@ func (r *Registry) visitArr(l Location, schema *base.Schema) (*Type, error) {
 		typ.Max = schema.MaxItems
 	}
 
+	typ.UniqueItems = ptr.Deref(schema.UniqueItems, false)
+
 	if schema.Items == nil {
 		typ.Elem = &Type{
 			Kind: TypeUnknown,
@@ -382,7 +494,15 @@ func (r *Registry) visitArr(l Location, schema *base.Schema) (*Type, error) {
 		}
 	}
 
-	if l.IsTopLevel() {
+	if schema.Contains != nil {
+		var err error
+		typ.Contains, err = r.visitContains(l.WithContains(), schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.hoists(l, typ) {
 		return &Type{
 			Kind: TypeRef,
 			Ref:  r.addDecl(l, typ, schema),
@@ -392,6 +512,201 @@ func (r *Registry) visitArr(l Location, schema *base.Schema) (*Type, error) {
 	return typ, nil
 }
 
+// visitContains visits the contains schema of an array. A contains schema without a type, such as
+// one holding only a const, takes the types of the array items. Its const or enum values are kept on
+// the result rather than turned into an enum declaration, since they only restrict matching items.
+func (r *Registry) visitContains(l Location, arr *base.Schema) (*Contains, error) {
+	c := &Contains{
+		Min: ptr.Deref(arr.MinContains, 1),
+		Max: arr.MaxContains,
+	}
+
+	if arr.Contains.IsReference() {
+		typ, err := r.visit(l, arr.Contains)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Types = []ContainsType{{Type: typ}}
+		return c, nil
+	}
+
+	schema := arr.Contains.Schema()
+	st, nullable := normalizeSchemaType(schema)
+
+	if len(st) == 0 && !nullable && arr.Items != nil && arr.Items.IsA() {
+		st, nullable = normalizeSchemaType(arr.Items.A.Schema())
+	}
+
+	if len(st) == 0 && !nullable {
+		return nil, fmt.Errorf("schema %s has no type", l)
+	}
+
+	// Both would be declared under the same location
+	if slices.Contains(st, "object") && slices.Contains(st, "array") {
+		return nil, fmt.Errorf("schema %s must not allow both objects and arrays", l)
+	}
+
+	if !slices.Contains(st, "object") && hasConditionals(schema) {
+		return nil, fmt.Errorf("schema %s uses dependentRequired, dependentSchemas or if/then/else, which are only supported on objects", l)
+	}
+
+	nodes := schema.Enum
+	if schema.Const != nil {
+		if len(schema.Enum) > 0 {
+			return nil, fmt.Errorf("schema %s must not have both const and enum", l)
+		}
+
+		nodes = []*yaml.Node{schema.Const}
+	}
+
+	var values []any
+	for i, n := range nodes {
+		v, err := decodeScalar(n)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s value %d: %w", l, i, err)
+		}
+
+		values = append(values, v)
+	}
+
+	plain := *schema
+	plain.Enum = nil
+	plain.Const = nil
+
+	for _, t := range st {
+		typ, err := r.visitType(l, t, &plain)
+		if err != nil {
+			return nil, err
+		}
+
+		ct := ContainsType{Type: typ}
+		for _, v := range values {
+			if v != nil && fitsType(v, typ) {
+				ct.Values = append(ct.Values, v)
+			}
+		}
+
+		// No item of the type can equal one of the values
+		if len(values) > 0 && len(ct.Values) == 0 {
+			continue
+		}
+
+		c.Types = append(c.Types, ct)
+	}
+
+	c.Nullable = nullable && (len(values) == 0 || slices.Contains(values, nil))
+
+	for i, v := range values {
+		fits := v == nil && nullable
+		for _, ct := range c.Types {
+			fits = fits || slices.Contains(ct.Values, v)
+		}
+
+		if !fits {
+			return nil, fmt.Errorf("schema %s value %d does not match the type of the schema", l, i)
+		}
+	}
+
+	return c, nil
+}
+
+// fitsType reports whether the decoded const or enum value v can be compared with values of the
+// scalar type typ in generated code.
+func fitsType(v any, typ *Type) bool {
+	switch x := v.(type) {
+	case nil:
+		return typ.Nullable
+	case string:
+		switch typ.Format {
+		case "date", "date-time", "binary", "byte":
+			return false
+		}
+		return typ.Kind == TypeString
+	case bool:
+		return typ.Kind == TypeBool
+	case int64:
+		switch typ.Kind {
+		case TypeInt32:
+			return x >= math.MinInt32 && x <= math.MaxInt32
+		case TypeInt64, TypeFloat64:
+			return true
+		}
+	case float64:
+		if typ.Kind == TypeFloat64 {
+			return true
+		}
+		return math.Trunc(x) == x && math.Abs(x) <= 1<<53 && fitsType(int64(x), typ)
+	}
+
+	return false
+}
+
+// visitTuple handles arrays described with prefixItems. Items below minItems become required
+// fields, the remaining ones are optional. Tuples are always hoisted into a declaration since
+// generators need to attach positional encoding to them.
+func (r *Registry) visitTuple(l Location, schema *base.Schema) (*Type, error) {
+	if schema.Contains != nil {
+		return nil, fmt.Errorf("schema %s uses contains, which is not supported on tuples", l)
+	}
+
+	typ := &Type{
+		Kind:   TypeTuple,
+		Fields: make([]Field, 0, len(schema.PrefixItems)),
+	}
+
+	minItems := ptr.Deref(schema.MinItems, 0)
+
+	for i, sp := range schema.PrefixItems {
+		ft, err := r.visit(l.WithPrefixItem(i), sp)
+		if err != nil {
+			return nil, err
+		}
+
+		itemSchema := sp.Schema()
+
+		typ.Fields = append(typ.Fields, Field{
+			Name:       strconv.Itoa(i),
+			Type:       ft,
+			Required:   int64(i) < minItems,
+			Deprecated: ptr.Deref(itemSchema.Deprecated, false),
+			Doc:        toDocLines(itemSchema.Description),
+		})
+	}
+
+	if schema.Items == nil {
+		typ.Elem = &Type{
+			Kind: TypeUnknown,
+		}
+	} else if schema.Items.IsB() {
+		if schema.Items.B {
+			typ.Elem = &Type{
+				Kind: TypeUnknown,
+			}
+		}
+	} else {
+		var err error
+		typ.Elem, err = r.visit(l.WithItems(), schema.Items.A)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Items below minItems are already required, and maxItems only matters when it can be exceeded
+	if minItems > int64(len(typ.Fields)) {
+		typ.Min = schema.MinItems
+	}
+
+	if schema.MaxItems != nil && (typ.Elem != nil || *schema.MaxItems < int64(len(typ.Fields))) {
+		typ.Max = schema.MaxItems
+	}
+
+	return &Type{
+		Kind: TypeRef,
+		Ref:  r.addDecl(l, typ, schema),
+	}, nil
+}
+
 func (r *Registry) visitObj(l Location, schema *base.Schema) (*Type, error) {
 	typ := &Type{
 		Kind: TypeObject,
@@ -410,8 +725,39 @@ func (r *Registry) visitObj(l Location, schema *base.Schema) (*Type, error) {
 		return nil, err
 	}
 
+	typ.RejectUnknown = typ.Elem == nil && r.additionalProps == AdditionalPropsReject &&
+		orderedmap.Len(schema.PatternProperties) == 0
+
+	for pair := schema.PatternProperties.First(); pair != nil; pair = pair.Next() {
+		pattern := pair.Key()
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("schema %s has an invalid patternProperties pattern %q: %w", l, pattern, err)
+		}
+
+		pt, err := r.visit(l.WithPatternProperty(pattern), pair.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		typ.PatternProps = append(typ.PatternProps, PatternProp{
+			Pattern: pattern,
+			Type:    pt,
+		})
+	}
+
+	if schema.PropertyNames != nil {
+		typ.PropNames, err = r.visitPropertyNames(l.WithPropertyNames(), schema.PropertyNames)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.visitConditionals(l, schema, typ); err != nil {
+		return nil, err
+	}
+
 	if orderedmap.Len(schema.Properties) == 0 {
-		if l.IsTopLevel() {
+		if r.hoists(l, typ) {
 			return &Type{
 				Kind: TypeRef,
 				Ref:  r.addDecl(l, typ, schema),
@@ -433,16 +779,30 @@ func (r *Registry) visitObj(l Location, schema *base.Schema) (*Type, error) {
 
 		propSchema := prop.Value().Schema()
 
+		xoptional, err := extString(propSchema, "x-go-optional")
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", l.WithProperty(name), err)
+		}
+
+		optional := r.optional
+		if xoptional != "" {
+			optional, err = ParseOptionalRepr(xoptional)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s: %w", l.WithProperty(name), err)
+			}
+		}
+
 		typ.Fields = append(typ.Fields, Field{
 			Name:       name,
 			Type:       ft,
 			Required:   slices.Contains(schema.Required, name),
 			Deprecated: ptr.Deref(propSchema.Deprecated, false),
 			Doc:        toDocLines(propSchema.Description),
+			Optional:   optional,
 		})
 	}
 
-	if l.IsTopLevel() {
+	if r.hoists(l, typ) {
 		return &Type{
 			Kind: TypeRef,
 			Ref:  r.addDecl(l, typ, schema),
@@ -452,8 +812,31 @@ func (r *Registry) visitObj(l Location, schema *base.Schema) (*Type, error) {
 	return typ, nil
 }
 
+// hoists reports whether the array or object typ found at l becomes a declaration.
+func (r *Registry) hoists(l Location, typ *Type) bool {
+	if l.IsTopLevel() {
+		return true
+	}
+
+	switch r.hoist {
+	case HoistObjects:
+		return typ.Kind == TypeObject && len(typ.Fields) > 0
+	case HoistAll:
+		return true
+	}
+
+	return false
+}
+
+// visitAdditionalProps returns the type of additional properties, or nil if they are not allowed.
+// Objects with declared properties that do not set additionalProperties are subject to the
+// additionalProperties policy of the registry.
 func (r *Registry) visitAdditionalProps(l Location, schema *base.Schema) (*Type, error) {
 	if schema.AdditionalProperties == nil {
+		if r.additionalProps != AdditionalPropsSpec && orderedmap.Len(schema.Properties) > 0 {
+			return nil, nil
+		}
+
 		return &Type{
 			Kind: TypeUnknown,
 		}, nil
@@ -472,6 +855,27 @@ func (r *Registry) visitAdditionalProps(l Location, schema *base.Schema) (*Type,
 	return r.visit(l.WithAdditionalProperties(), schema.AdditionalProperties.A)
 }
 
+// visitPropertyNames visits a propertyNames schema. Property names are always strings, so a schema
+// without a type is treated as a string schema.
+func (r *Registry) visitPropertyNames(l Location, sp *base.SchemaProxy) (*Type, error) {
+	if sp.IsReference() {
+		return r.visit(l, sp)
+	}
+
+	schema := sp.Schema()
+	st, _ := normalizeSchemaType(schema)
+
+	if len(st) == 0 {
+		return r.visitStr(l, schema)
+	}
+
+	if len(st) > 1 || st[0] != "string" {
+		return nil, fmt.Errorf("schema %s must be a string schema", l)
+	}
+
+	return r.visitStr(l, schema)
+}
+
 func toDocLines(doc string) []string {
 	if doc == "" {
 		return nil
@@ -481,6 +885,41 @@ func toDocLines(doc string) []string {
 	return strings.Split(normalized, "\n")
 }
 
+// extBool decodes the boolean extension key of schema, which is false if not set.
+func extBool(schema *base.Schema, key string) (bool, error) {
+	if orderedmap.Len(schema.Extensions) == 0 {
+		return false, nil
+	}
+
+	node, found := schema.Extensions.Get(key)
+	if !found {
+		return false, nil
+	}
+
+	var v bool
+	if err := node.Decode(&v); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return v, nil
+}
+
+func extString(schema *base.Schema, key string) (string, error) {
+	if orderedmap.Len(schema.Extensions) == 0 {
+		return "", nil
+	}
+
+	node, found := schema.Extensions.Get(key)
+	if !found {
+		return "", nil
+	}
+
+	var v string
+	if err := node.Decode(&v); err != nil {
+		return "", fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return v, nil
+}
+
 func makeConsts[T any](schema *base.Schema, build func(*EnumConst, T)) ([]EnumConst, error) {
 	if len(schema.Enum) == 0 {
 		return nil, nil
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maketaio/openapi/internal/util/ptr"
+)
+
+func TestVisitContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		contains string
+		want     *Contains
+	}{
+		{
+			name:     "const takes the item type",
+			contains: "{const: 5}",
+			want: &Contains{
+				Types: []ContainsType{{Type: &Type{Kind: TypeInt64}, Values: []any{int64(5)}}},
+				Min:   1,
+			},
+		},
+		{
+			name:     "reference",
+			contains: "{$ref: '#/components/schemas/Item'}",
+			want: &Contains{
+				Types: []ContainsType{{Type: &Type{Kind: TypeRef, Ref: "Item"}}},
+				Min:   1,
+			},
+		},
+		{
+			name:     "types split with their values",
+			contains: "{type: [string, number, 'null'], enum: [a, 1.5, 2]}",
+			want: &Contains{
+				Types: []ContainsType{
+					{Type: &Type{Kind: TypeString}, Values: []any{"a"}},
+					{Type: &Type{Kind: TypeFloat64}, Values: []any{1.5, int64(2)}},
+				},
+				Min: 1,
+			},
+		},
+		{
+			name:     "types without fitting values are dropped",
+			contains: "{type: [string, boolean], const: true}",
+			want: &Contains{
+				Types: []ContainsType{{Type: &Type{Kind: TypeBool}, Values: []any{true}}},
+				Min:   1,
+			},
+		},
+		{
+			name:     "null",
+			contains: "{type: [string, 'null'], enum: [null]}",
+			want: &Contains{
+				Nullable: true,
+				Min:      1,
+			},
+		},
+		{
+			name:     "object",
+			contains: "{type: object, properties: {id: {type: integer}}}",
+			want: &Contains{
+				Types: []ContainsType{{Type: &Type{Kind: TypeRef, Ref: "List/contains"}}},
+				Min:   1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := collect(t, `
+    Item:
+      type: object
+    List:
+      type: array
+      items: {type: integer}
+      contains: `+test.contains+`
+`)
+			list, _ := r.Get("List")
+			if got := list.Type.Contains; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+
+			// Values must not be declared as an enum
+			for _, id := range r.ids {
+				if len(r.decls[id].Type.Enum) > 0 {
+					t.Errorf("unexpected enum declaration %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestVisitContainsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contains string
+		want     string
+	}{
+		{"const of another type", "{type: string, const: 1}", "schema List/contains value 0 does not match the type of the schema"},
+		{"fraction for an integer", "{type: integer, enum: [1, 1.5]}", "schema List/contains value 1 does not match"},
+		{"unexpected null", "{type: integer, const: null}", "schema List/contains value 0 does not match"},
+		{"object value", "{type: object, const: {a: 1}}", "schema List/contains value 0: only scalar values are supported"},
+		{"const and enum", "{type: integer, const: 1, enum: [1]}", "must not have both const and enum"},
+		{"objects and arrays", "{type: [object, array]}", "must not allow both objects and arrays"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := tryCollect(`
+    List:
+      type: array
+      contains: ` + test.contains + `
+`)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestVisitTupleBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		min, max *int64
+	}{
+		{"required prefix", "minItems: 2, items: false", nil, nil},
+		{"min beyond prefix", "minItems: 3", ptr.To[int64](3), nil},
+		{"min beyond closed prefix", "minItems: 3, items: false", ptr.To[int64](3), nil},
+		{"max with rest", "maxItems: 4", nil, ptr.To[int64](4)},
+		{"max below closed prefix", "maxItems: 1, items: false", nil, ptr.To[int64](1)},
+		{"max covering closed prefix", "maxItems: 2, items: false", nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := collect(t, `
+    List:
+      {type: array, prefixItems: [{type: string}, {type: string}], `+test.schema+`}
+`)
+			list, _ := r.Get("List")
+			if got := list.Type.Min; !reflect.DeepEqual(got, test.min) {
+				t.Errorf("got min %d, want %d", ptr.Deref(got, -1), ptr.Deref(test.min, -1))
+			}
+			if got := list.Type.Max; !reflect.DeepEqual(got, test.max) {
+				t.Errorf("got max %d, want %d", ptr.Deref(got, -1), ptr.Deref(test.max, -1))
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Merge describes declarations found to be structurally identical by Dedup.
+type Merge struct {
+	// Canonical is the ID of the declaration that was kept.
+	Canonical string
+	// Duplicates are the IDs of the declarations that became references to Canonical, in
+	// insertion order.
+	Duplicates []string
+}
+
+// Dedup merges declarations that have the same shape, constraints included, into one canonical
+// declaration. The others are turned into references to it, which generators usually emit as
+// aliases, and keep their own ID, name and documentation. Documentation and deprecation do not take
+// part in the comparison. Schemas defined at the top level of components.schemas are preferred as canonical
+// declarations, then the first one in insertion order. Enums are never merged.
+//
+// References are compared by the declaration they point to once it is merged, so that objects
+// holding identical nested objects are merged as well. Recursive declarations are only merged when
+// they are identical, references to themselves included.
+func (r *Registry) Dedup() []Merge {
+	canon := map[string]string{}
+
+	for {
+		groups := map[string][]string{}
+		var keys []string
+
+		for _, id := range r.ids {
+			decl := r.decls[id]
+			// Enums declare constants named after them, which would be lost with the declaration
+			if decl.Type.Kind == TypeRef || len(decl.Type.Enum) > 0 {
+				continue
+			}
+			if _, merged := canon[id]; merged {
+				continue
+			}
+
+			key := shapeKey(decl, canon)
+			if _, found := groups[key]; !found {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], id)
+		}
+
+		merged := false
+		for _, key := range keys {
+			ids := groups[key]
+			if len(ids) < 2 {
+				continue
+			}
+
+			c := ids[0]
+			for _, id := range ids {
+				if isComponent(r.decls[id].Loc) {
+					c = id
+					break
+				}
+			}
+
+			for _, id := range ids {
+				if id != c {
+					canon[id] = c
+				}
+			}
+			merged = true
+		}
+
+		if !merged {
+			break
+		}
+	}
+
+	var merges []Merge
+	index := map[string]int{}
+
+	for _, id := range r.ids {
+		if _, ok := canon[id]; !ok {
+			continue
+		}
+
+		c := canonical(canon, id)
+
+		r.decls[id].Type = &Type{
+			Kind: TypeRef,
+			Ref:  c,
+		}
+
+		i, found := index[c]
+		if !found {
+			i = len(merges)
+			index[c] = i
+			merges = append(merges, Merge{Canonical: c})
+		}
+		merges[i].Duplicates = append(merges[i].Duplicates, id)
+	}
+
+	slices.SortStableFunc(merges, func(a, b Merge) int {
+		return slices.Index(r.ids, a.Canonical) - slices.Index(r.ids, b.Canonical)
+	})
+
+	return merges
+}
+
+// canonical returns the declaration that id was merged into, or id if it was not merged. A canonical
+// declaration may itself be merged in a later round.
+func canonical(canon map[string]string, id string) string {
+	for {
+		next, ok := canon[id]
+		if !ok {
+			return id
+		}
+		id = next
+	}
+}
+
+// isComponent reports whether l is a schema defined at the top level of components.schemas.
+func isComponent(l Location) bool {
+	return l.IsTopLevel() && l.Op == nil
+}
+
+// shapeKey returns a string that is equal for declarations with the same shape. References to
+// merged declarations are replaced with their canonical declaration.
+func shapeKey(decl *Declaration, canon map[string]string) string {
+	shape := struct {
+		Type    *Type
+		SQLJSON bool
+	}{
+		Type:    withoutDocs(decl.Type, canon),
+		SQLJSON: decl.SQLJSON,
+	}
+
+	// Types only hold strings, numbers, booleans and slices of them, which always encode.
+	data, _ := json.Marshal(shape)
+	return string(data)
+}
+
+// withoutDocs returns a copy of typ without documentation or deprecation and with references to merged
+// declarations replaced with their canonical declaration.
+func withoutDocs(typ *Type, canon map[string]string) *Type {
+	if typ == nil {
+		return nil
+	}
+
+	c := *typ
+
+	if c.Kind == TypeRef {
+		c.Ref = canonical(canon, c.Ref)
+	}
+
+	if len(typ.Enum) > 0 {
+		c.Enum = make([]EnumConst, len(typ.Enum))
+		for i, e := range typ.Enum {
+			e.Doc = nil
+			c.Enum[i] = e
+		}
+	}
+
+	if len(typ.Fields) > 0 {
+		c.Fields = make([]Field, len(typ.Fields))
+		for i, f := range typ.Fields {
+			f.Doc = nil
+			f.Deprecated = false
+			f.Type = withoutDocs(f.Type, canon)
+			c.Fields[i] = f
+		}
+	}
+
+	if len(typ.PatternProps) > 0 {
+		c.PatternProps = make([]PatternProp, len(typ.PatternProps))
+		for i, pp := range typ.PatternProps {
+			pp.Type = withoutDocs(pp.Type, canon)
+			c.PatternProps[i] = pp
+		}
+	}
+
+	c.Elem = withoutDocs(typ.Elem, canon)
+	c.PropNames = withoutDocs(typ.PropNames, canon)
+
+	if typ.Contains != nil {
+		contains := *typ.Contains
+		contains.Types = make([]ContainsType, len(typ.Contains.Types))
+		for i, ct := range typ.Contains.Types {
+			ct.Type = withoutDocs(ct.Type, canon)
+			contains.Types[i] = ct
+		}
+		c.Contains = &contains
+	}
+
+	return &c
+}
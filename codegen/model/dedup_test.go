@@ -0,0 +1,104 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+)
+
+// collect builds a registry from an OpenAPI document holding the given components.schemas.
+func collect(t *testing.T, schemas string) *Registry {
+	t.Helper()
+
+	r, err := tryCollect(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// tryCollect is collect for documents that may be rejected.
+func tryCollect(schemas string) (*Registry, error) {
+	spec := "openapi: 3.1.0\ninfo: {title: t, version: \"1\"}\npaths: {}\ncomponents:\n  schemas:\n" + schemas
+
+	doc, err := libopenapi.NewDocument([]byte(spec))
+	if err != nil {
+		return nil, err
+	}
+	dm, err := doc.BuildV3Model()
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewRegistry()
+	if err := r.Collect(dm); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func TestDedup(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemas string
+		want    []Merge
+	}{
+		{
+			name: "objects",
+			schemas: `
+    A:
+      type: object
+      properties:
+        name: {type: string}
+    B:
+      type: object
+      properties:
+        name: {type: string}
+`,
+			want: []Merge{{Canonical: "A", Duplicates: []string{"B"}}},
+		},
+		{
+			name: "constraints differ",
+			schemas: `
+    A:
+      type: string
+      maxLength: 10
+    B:
+      type: string
+      maxLength: 20
+`,
+		},
+		{
+			name: "enums",
+			schemas: `
+    Status:
+      type: string
+      enum: [active, inactive]
+    State:
+      type: string
+      enum: [active, inactive]
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := collect(t, test.schemas)
+
+			got := r.Dedup()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Dedup() = %v, want %v", got, test.want)
+			}
+
+			for _, m := range got {
+				for _, id := range m.Duplicates {
+					decl, _ := r.Get(id)
+					if decl.Type.Kind != TypeRef || decl.Type.Ref != m.Canonical {
+						t.Errorf("%s is not a reference to %s", id, m.Canonical)
+					}
+				}
+			}
+		})
+	}
+}
@@ -6,19 +6,26 @@
 //
 //   - Declaration: A named, top-level unit of code generation. A Declaration is created for
 //     any schema defined at components.schemas, and for certain nested schemas that are
-//     "hoisted" (e.g., nested object schemas) and for enums on simple types. Declarations
-//     are addressable by an ID (see Location) and contain a Type that describes their shape.
+//     "hoisted" according to the HoistPolicy of the Registry (by default nested object schemas),
+//     for enums on simple types and for tuples. Declarations are addressable by an ID (see
+//     Location) and contain a Type that describes their shape.
 //     Think “what will become a type/alias/const block in the target language”.
 //
 //   - Type: A structural description used to model shapes. Types can be primitive
-//     (int32, string, …), composite (object, array, map), or a TypeRef pointing to a
+//     (int32, string, …), composite (object, array, tuple, map), or a TypeRef pointing to a
 //     Declaration by ID. Types are anonymous by themselves; they become named/embeddable
 //     only when wrapped by a Declaration.
 //
 //   - Location: Identifies where a Declaration came from. For top-level schemas it’s just the
 //     schema name; for hoisted nested schemas it is the root schema name plus a path of
-//     segments (e.g., /properties/address, /items, /additionalProperties). The Location’s
-//     string form is used as the Declaration ID.
+//     segments (e.g., /properties/address, /items, /prefixItems/0, /additionalProperties). The Location’s
+//     string form is used as the Declaration ID. Schemas defined inline in an operation are rooted at
+//     the operation instead, e.g. operations/createUser/requestBody/application~1json or
+//     operations/createUser/responses/201/headers/Location.
+//
+//   - Operation: An HTTP method on a path, with its request body and one Response per status
+//     code (including default). Bodies are listed per media type and response headers are
+//     typed. Referenced schemas are used as is; inline ones are hoisted into Declarations.
 //
 // # Example
 //
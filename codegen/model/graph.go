@@ -0,0 +1,220 @@
+package model
+
+import "slices"
+
+// Dependencies returns the IDs of the declarations that the declaration id references directly, in
+// the order they are first referenced.
+func (r *Registry) Dependencies(id string) []string {
+	decl, ok := r.decls[id]
+	if !ok {
+		return nil
+	}
+
+	var deps []string
+	r.walkRefs(decl.Type, func(ref string) {
+		if !slices.Contains(deps, ref) {
+			deps = append(deps, ref)
+		}
+	})
+	return deps
+}
+
+// Dependents returns the IDs of the declarations that reference the declaration id directly, in
+// insertion order.
+func (r *Registry) Dependents(id string) []string {
+	var dependents []string
+	for _, other := range r.ids {
+		if slices.Contains(r.Dependencies(other), id) {
+			dependents = append(dependents, other)
+		}
+	}
+	return dependents
+}
+
+// OperationDependencies returns the IDs of the declarations that the operation opID references
+// directly from its request body, response bodies and response headers, in the order they are
+// first referenced.
+func (r *Registry) OperationDependencies(opID string) []string {
+	op, ok := r.GetOperation(opID)
+	if !ok {
+		return nil
+	}
+
+	var deps []string
+	add := func(ref string) {
+		if !slices.Contains(deps, ref) {
+			deps = append(deps, ref)
+		}
+	}
+
+	if op.RequestBody != nil {
+		for _, mt := range op.RequestBody.Content {
+			r.walkRefs(mt.Type, add)
+		}
+	}
+
+	for _, resp := range op.Responses {
+		for _, h := range resp.Headers {
+			r.walkRefs(h.Type, add)
+		}
+		for _, mt := range resp.Content {
+			r.walkRefs(mt.Type, add)
+		}
+	}
+
+	return deps
+}
+
+// StronglyConnectedComponents returns the declarations grouped into strongly connected components
+// of the reference graph. A component with more than one declaration, or with a declaration that
+// references itself, is a cycle. Components come in topological order, so that a component only
+// references components that come before it. Declarations within a component are in insertion
+// order.
+func (r *Registry) StronglyConnectedComponents() [][]string {
+	// Tarjan's algorithm, which finds components in reverse topological order of the graph. Since
+	// edges go from a declaration to its dependencies, that is dependencies first.
+	index := map[string]int{}
+	lowlink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var sccs [][]string
+
+	var connect func(id string)
+	connect = func(id string) {
+		index[id] = len(index)
+		lowlink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, dep := range r.Dependencies(id) {
+			if _, visited := index[dep]; !visited {
+				connect(dep)
+				lowlink[id] = min(lowlink[id], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[id] = min(lowlink[id], index[dep])
+			}
+		}
+
+		if lowlink[id] != index[id] {
+			return
+		}
+
+		var scc []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			scc = append(scc, top)
+			if top == id {
+				break
+			}
+		}
+
+		slices.SortFunc(scc, func(a, b string) int {
+			return slices.Index(r.ids, a) - slices.Index(r.ids, b)
+		})
+		sccs = append(sccs, scc)
+	}
+
+	for _, id := range r.ids {
+		if _, visited := index[id]; !visited {
+			connect(id)
+		}
+	}
+
+	return sccs
+}
+
+// TopologicalOrder returns the IDs of all declarations such that a declaration comes after the
+// declarations it references. Declarations in a cycle cannot satisfy this and are kept together in
+// insertion order, see StronglyConnectedComponents.
+func (r *Registry) TopologicalOrder() []string {
+	var order []string
+	for _, scc := range r.StronglyConnectedComponents() {
+		order = append(order, scc...)
+	}
+	return order
+}
+
+// RetainOperations removes the operations for which keep returns false. The declarations they used
+// are left in place; call Prune to remove the ones that are no longer needed.
+func (r *Registry) RetainOperations(keep func(op *Operation) bool) {
+	r.ops = slices.DeleteFunc(r.ops, func(op *Operation) bool {
+		return !keep(op)
+	})
+}
+
+// Prune removes the declarations that cannot be reached from roots or from the operations of the
+// registry, and returns their IDs in insertion order. Roots are declaration IDs.
+func (r *Registry) Prune(roots ...string) []string {
+	reachable := map[string]bool{}
+
+	var reach func(id string)
+	reach = func(id string) {
+		if reachable[id] {
+			return
+		}
+		if _, ok := r.decls[id]; !ok {
+			return
+		}
+
+		reachable[id] = true
+		for _, dep := range r.Dependencies(id) {
+			reach(dep)
+		}
+	}
+
+	for _, id := range roots {
+		reach(id)
+	}
+
+	for _, op := range r.ops {
+		for _, id := range r.OperationDependencies(op.ID) {
+			reach(id)
+		}
+	}
+
+	var pruned []string
+	r.ids = slices.DeleteFunc(r.ids, func(id string) bool {
+		if reachable[id] {
+			return false
+		}
+
+		pruned = append(pruned, id)
+		delete(r.decls, id)
+		return true
+	})
+
+	return pruned
+}
+
+// walkRefs calls fn with the ID of each declaration referenced by typ, without following the
+// references themselves. References to unknown declarations are skipped.
+func (r *Registry) walkRefs(typ *Type, fn func(ref string)) {
+	if typ == nil {
+		return
+	}
+
+	if typ.Kind == TypeRef {
+		if _, ok := r.decls[typ.Ref]; ok {
+			fn(typ.Ref)
+		}
+		return
+	}
+
+	for _, f := range typ.Fields {
+		r.walkRefs(f.Type, fn)
+	}
+
+	for _, pp := range typ.PatternProps {
+		r.walkRefs(pp.Type, fn)
+	}
+
+	r.walkRefs(typ.Elem, fn)
+	r.walkRefs(typ.PropNames, fn)
+	if typ.Contains != nil {
+		for _, ct := range typ.Contains.Types {
+			r.walkRefs(ct.Type, fn)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+// graphSchemas has a chain (A -> B -> C), a self-reference (Node), a cycle of two (Even <-> Odd)
+// that Root depends on, and an unreferenced declaration (Lone).
+const graphSchemas = `
+    A:
+      type: object
+      properties:
+        b: {$ref: '#/components/schemas/B'}
+        b2: {$ref: '#/components/schemas/B'}
+    B:
+      type: array
+      items: {$ref: '#/components/schemas/C'}
+    C:
+      type: string
+    Node:
+      type: object
+      properties:
+        next: {$ref: '#/components/schemas/Node'}
+    Root:
+      type: object
+      properties:
+        even: {$ref: '#/components/schemas/Even'}
+    Even:
+      type: object
+      properties:
+        odd: {$ref: '#/components/schemas/Odd'}
+    Odd:
+      type: object
+      properties:
+        even: {$ref: '#/components/schemas/Even'}
+    Lone:
+      type: integer
+`
+
+func TestDependencies(t *testing.T) {
+	r := collect(t, graphSchemas)
+
+	tests := []struct {
+		id         string
+		deps       []string
+		dependents []string
+	}{
+		{"A", []string{"B"}, nil},
+		{"B", []string{"C"}, []string{"A"}},
+		{"C", nil, []string{"B"}},
+		{"Node", []string{"Node"}, []string{"Node"}},
+		{"Even", []string{"Odd"}, []string{"Root", "Odd"}},
+		{"Lone", nil, nil},
+		{"Missing", nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			if got := r.Dependencies(test.id); !reflect.DeepEqual(got, test.deps) {
+				t.Errorf("Dependencies = %v, want %v", got, test.deps)
+			}
+			if got := r.Dependents(test.id); !reflect.DeepEqual(got, test.dependents) {
+				t.Errorf("Dependents = %v, want %v", got, test.dependents)
+			}
+		})
+	}
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	r := collect(t, graphSchemas)
+
+	want := [][]string{{"C"}, {"B"}, {"A"}, {"Node"}, {"Even", "Odd"}, {"Root"}, {"Lone"}}
+	if got := r.StronglyConnectedComponents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	wantOrder := []string{"C", "B", "A", "Node", "Even", "Odd", "Root", "Lone"}
+	if got := r.TopologicalOrder(); !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("got order %v, want %v", got, wantOrder)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name   string
+		roots  []string
+		pruned []string
+	}{
+		{"no roots", nil, []string{"A", "B", "C", "Node", "Root", "Even", "Odd", "Lone"}},
+		{"chain", []string{"A"}, []string{"Node", "Root", "Even", "Odd", "Lone"}},
+		{"self-reference", []string{"Node"}, []string{"A", "B", "C", "Root", "Even", "Odd", "Lone"}},
+		{"into a cycle", []string{"Odd"}, []string{"A", "B", "C", "Node", "Root", "Lone"}},
+		{"unknown root", []string{"Missing", "C"}, []string{"A", "B", "Node", "Root", "Even", "Odd", "Lone"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := collect(t, graphSchemas)
+			if got := r.Prune(test.roots...); !reflect.DeepEqual(got, test.pruned) {
+				t.Errorf("got %v, want %v", got, test.pruned)
+			}
+			for _, id := range test.pruned {
+				if _, ok := r.Get(id); ok {
+					t.Errorf("%s is still declared", id)
+				}
+			}
+		})
+	}
+}
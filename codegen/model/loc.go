@@ -1,5 +1,11 @@
 package model
 
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
 type SegmentKind int
 
 const (
@@ -9,6 +15,14 @@ const (
 	SegmentAdditionalProperties
 	// SegmentItems is used for array items segments.
 	SegmentItems
+	// SegmentPrefixItem is used for tuple item segments. Name holds the item index.
+	SegmentPrefixItem
+	// SegmentPatternProperty is used for pattern properties segments. Name holds the pattern.
+	SegmentPatternProperty
+	// SegmentPropertyNames is used for property names segments.
+	SegmentPropertyNames
+	// SegmentContains is used for array contains segments.
+	SegmentContains
 )
 
 // Segment represents a segment of a path to a model.
@@ -21,16 +35,60 @@ type Segment struct {
 
 // Location represents a location of a model. If a model is a top level schema, then its loc is simply
 // the schema name. If a model is "hoisted" because it was a nested object schema, then its loc
-// contains the parent schema name plus the path to the nested schema.
+// contains the parent schema name plus the path to the nested schema. Schemas defined inline in
+// operations have no Root; Op tells where in the operation they were found instead.
 type Location struct {
 	// Root is the name of the top level schema.
 	Root string
+	// Op is set for schemas defined in an operation's request body, responses or response headers.
+	Op *OpLocation
 	// Path contains the path to the nested model, if applicable.
 	Path []Segment
 }
 
+type OpPart int
+
+const (
+	// OpRequestBody is used for request body schemas.
+	OpRequestBody OpPart = iota
+	// OpResponseBody is used for response body schemas.
+	OpResponseBody
+	// OpResponseHeader is used for response header schemas.
+	OpResponseHeader
+)
+
+// OpLocation identifies a schema root inside an operation.
+type OpLocation struct {
+	// OperationID is the ID of the operation the schema belongs to.
+	OperationID string
+	// Part shows which part of the operation the schema was found in.
+	Part OpPart
+	// Status is the response status code ("200", "4XX" or "default"), for responses.
+	Status string
+	// MediaType is the content type of the request or response body, for bodies.
+	MediaType string
+	// Header is the header name, for response headers.
+	Header string
+}
+
+func (o *OpLocation) String() string {
+	loc := "operations/" + escapeSegment(o.OperationID)
+	switch o.Part {
+	case OpRequestBody:
+		loc += "/requestBody/" + escapeSegment(o.MediaType)
+	case OpResponseBody:
+		loc += "/responses/" + o.Status + "/" + escapeSegment(o.MediaType)
+	case OpResponseHeader:
+		loc += "/responses/" + o.Status + "/headers/" + escapeSegment(o.Header)
+	}
+	return loc
+}
+
 func (l Location) String() string {
 	loc := l.Root
+	if l.Op != nil {
+		loc = l.Op.String()
+	}
 	for _, seg := range l.Path {
 		switch seg.Kind {
 		case SegmentProperty:
@@ -39,6 +97,14 @@ func (l Location) String() string {
 			loc += "/additionalProperties"
 		case SegmentItems:
 			loc += "/items"
+		case SegmentPrefixItem:
+			loc += "/prefixItems/" + seg.Name
+		case SegmentPatternProperty:
+			loc += "/patternProperties/" + escapeSegment(seg.Name)
+		case SegmentPropertyNames:
+			loc += "/propertyNames"
+		case SegmentContains:
+			loc += "/contains"
 		}
 	}
 	return loc
@@ -49,29 +115,61 @@ func (l Location) IsTopLevel() bool {
 }
 
 func (l Location) WithProperty(name string) Location {
-	return Location{
-		Root: l.Root,
-		Path: append(l.Path, Segment{
-			Kind: SegmentProperty,
-			Name: name,
-		}),
-	}
+	return l.with(Segment{
+		Kind: SegmentProperty,
+		Name: name,
+	})
 }
 
 func (l Location) WithAdditionalProperties() Location {
-	return Location{
-		Root: l.Root,
-		Path: append(l.Path, Segment{
-			Kind: SegmentAdditionalProperties,
-		}),
-	}
+	return l.with(Segment{
+		Kind: SegmentAdditionalProperties,
+	})
 }
 
 func (l Location) WithItems() Location {
+	return l.with(Segment{
+		Kind: SegmentItems,
+	})
+}
+
+func (l Location) WithPrefixItem(index int) Location {
+	return l.with(Segment{
+		Kind: SegmentPrefixItem,
+		Name: strconv.Itoa(index),
+	})
+}
+
+func (l Location) WithPatternProperty(pattern string) Location {
+	return l.with(Segment{
+		Kind: SegmentPatternProperty,
+		Name: pattern,
+	})
+}
+
+func (l Location) WithPropertyNames() Location {
+	return l.with(Segment{
+		Kind: SegmentPropertyNames,
+	})
+}
+
+func (l Location) WithContains() Location {
+	return l.with(Segment{
+		Kind: SegmentContains,
+	})
+}
+
+// with returns a copy of l with seg appended. The path is always copied so sibling locations never
+// share a backing array.
+func (l Location) with(seg Segment) Location {
 	return Location{
 		Root: l.Root,
-		Path: append(l.Path, Segment{
-			Kind: SegmentItems,
-		}),
+		Op:   l.Op,
+		Path: append(slices.Clip(l.Path), seg),
 	}
 }
+
+// escapeSegment escapes a segment name as a JSON Pointer reference token (RFC 6901).
+func escapeSegment(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,279 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/maketaio/openapi/internal/util/ptr"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// Operation represents a single API operation, i.e. an HTTP method on a path, together with the
+// shapes of its request body and responses.
+type Operation struct {
+	// ID is the operationId. If the spec does not set one, it is derived from the method and path.
+	ID         string
+	Method     string // Upper-case HTTP method
+	Path       string
+	Tags       []string
+	Doc        []string
+	Deprecated bool
+
+	RequestBody *RequestBody // nil if the operation accepts no body
+	Responses   []*Response  // In document order, with the default response last
+}
+
+// RequestBody describes the request body of an operation.
+type RequestBody struct {
+	Required bool
+	Doc      []string
+	Content  []MediaType
+}
+
+// Response describes the response of an operation for one status code.
+type Response struct {
+	// Status is the status code as written in the spec: "200", "4XX" or "default".
+	Status  string
+	Doc     []string
+	Headers []Header
+	Content []MediaType
+}
+
+// IsDefault reports whether r is the default response of its operation.
+func (r *Response) IsDefault() bool {
+	return r.Status == "default"
+}
+
+// MediaType describes a body for one content type.
+type MediaType struct {
+	// Name is the content type, e.g. "application/json".
+	Name string
+	// Type is the shape of the body, or nil if the spec does not define a schema for it.
+	Type *Type
+}
+
+// Header describes a response header.
+type Header struct {
+	Name       string
+	Type       *Type
+	Required   bool
+	Deprecated bool
+	Doc        []string
+}
+
+// GetOperation returns the operation with the given ID.
+func (r *Registry) GetOperation(id string) (*Operation, bool) {
+	for _, op := range r.ops {
+		if op.ID == id {
+			return op, true
+		}
+	}
+	return nil, false
+}
+
+// RangeOperations iterates operations in document order. To stop early, return false.
+func (r *Registry) RangeOperations(fn func(op *Operation) bool) {
+	for _, op := range r.ops {
+		if !fn(op) {
+			return
+		}
+	}
+}
+
+func (r *Registry) collectOperations(paths *v3.Paths) error {
+	if paths == nil || paths.PathItems == nil {
+		return nil
+	}
+
+	seen := map[string]string{}
+	for pp := paths.PathItems.First(); pp != nil; pp = pp.Next() {
+		for mp := pp.Value().GetOperations().First(); mp != nil; mp = mp.Next() {
+			method := strings.ToUpper(mp.Key())
+			op, err := r.visitOperation(method, pp.Key(), mp.Value())
+			if err != nil {
+				return err
+			}
+
+			if prev, ok := seen[op.ID]; ok {
+				return fmt.Errorf("operation %s %s has the same ID %q as %s", method, pp.Key(), op.ID, prev)
+			}
+
+			seen[op.ID] = method + " " + pp.Key()
+			r.ops = append(r.ops, op)
+		}
+	}
+
+	return nil
+}
+
+func (r *Registry) visitOperation(method, path string, o *v3.Operation) (*Operation, error) {
+	op := &Operation{
+		ID:         o.OperationId,
+		Method:     method,
+		Path:       path,
+		Tags:       o.Tags,
+		Doc:        toDocLines(opDoc(o)),
+		Deprecated: ptr.Deref(o.Deprecated, false),
+	}
+
+	if op.ID == "" {
+		op.ID = deriveOperationID(method, path)
+	}
+
+	if rb := o.RequestBody; rb != nil {
+		content, err := r.visitContent(&OpLocation{OperationID: op.ID, Part: OpRequestBody}, rb.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		op.RequestBody = &RequestBody{
+			Required: ptr.Deref(rb.Required, false),
+			Doc:      toDocLines(rb.Description),
+			Content:  content,
+		}
+	}
+
+	if o.Responses == nil {
+		return op, nil
+	}
+
+	if o.Responses.Codes != nil {
+		for pair := o.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+			resp, err := r.visitResponse(op.ID, pair.Key(), pair.Value())
+			if err != nil {
+				return nil, err
+			}
+			op.Responses = append(op.Responses, resp)
+		}
+	}
+
+	if o.Responses.Default != nil {
+		resp, err := r.visitResponse(op.ID, "default", o.Responses.Default)
+		if err != nil {
+			return nil, err
+		}
+		op.Responses = append(op.Responses, resp)
+	}
+
+	return op, nil
+}
+
+func (r *Registry) visitResponse(opID, status string, resp *v3.Response) (*Response, error) {
+	status = strings.ToUpper(status)
+	if status == "DEFAULT" {
+		status = "default"
+	}
+
+	res := &Response{
+		Status: status,
+		Doc:    toDocLines(resp.Description),
+	}
+
+	content, err := r.visitContent(&OpLocation{OperationID: opID, Part: OpResponseBody, Status: status}, resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	res.Content = content
+
+	if resp.Headers == nil {
+		return res, nil
+	}
+
+	for pair := resp.Headers.First(); pair != nil; pair = pair.Next() {
+		name, h := pair.Key(), pair.Value()
+
+		// Content-Type is described by the response content and must not be set by hand.
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+
+		l := Location{Op: &OpLocation{OperationID: opID, Part: OpResponseHeader, Status: status, Header: name}}
+
+		if h.Schema == nil {
+			return nil, fmt.Errorf("header %s has no schema, which is not supported at the moment", l)
+		}
+
+		typ, err := r.visitOpSchema(l, h.Schema)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Headers = append(res.Headers, Header{
+			Name:       name,
+			Type:       typ,
+			Required:   h.Required,
+			Deprecated: h.Deprecated,
+			Doc:        toDocLines(h.Description),
+		})
+	}
+
+	return res, nil
+}
+
+func (r *Registry) visitContent(ol *OpLocation, content *orderedmap.Map[string, *v3.MediaType]) ([]MediaType, error) {
+	if content == nil {
+		return nil, nil
+	}
+
+	var res []MediaType
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		mt := MediaType{Name: pair.Key()}
+		if sp := pair.Value().Schema; sp != nil {
+			l := *ol
+			l.MediaType = mt.Name
+
+			typ, err := r.visitOpSchema(Location{Op: &l}, sp)
+			if err != nil {
+				return nil, err
+			}
+			mt.Type = typ
+		}
+		res = append(res, mt)
+	}
+
+	return res, nil
+}
+
+// visitOpSchema visits a schema found in an operation. References are used as is rather than
+// producing an alias declaration, while inline schemas are hoisted just like top-level schemas.
+func (r *Registry) visitOpSchema(l Location, sp *base.SchemaProxy) (*Type, error) {
+	if sp.IsReference() {
+		parts := strings.Split(sp.GetReference(), "/")
+		return &Type{
+			Kind: TypeRef,
+			Ref:  parts[len(parts)-1],
+		}, nil
+	}
+
+	return r.visit(l, sp)
+}
+
+func opDoc(o *v3.Operation) string {
+	switch {
+	case o.Summary == "":
+		return o.Description
+	case o.Description == "":
+		return o.Summary
+	default:
+		return o.Summary + "\n\n" + o.Description
+	}
+}
+
+// deriveOperationID builds an operation ID from the method and path, e.g. "GET /users/{id}"
+// becomes "getUsersId".
+func deriveOperationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+
+	words := strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package model
+
+import "fmt"
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// AdditionalPropsPolicy decides how objects that declare properties but do not set
+// additionalProperties are modeled. Objects without declared properties are free-form and always
+// follow the specification.
+type AdditionalPropsPolicy int
+
+const (
+	// AdditionalPropsSpec follows the specification: undeclared properties are allowed and kept.
+	AdditionalPropsSpec AdditionalPropsPolicy = iota
+	// AdditionalPropsIgnore drops undeclared properties while decoding.
+	AdditionalPropsIgnore
+	// AdditionalPropsReject rejects undeclared properties while decoding. It also applies to
+	// objects that set additionalProperties to false.
+	AdditionalPropsReject
+)
+
+var additionalPropsPolicyNames = map[AdditionalPropsPolicy]string{
+	AdditionalPropsSpec:   "spec",
+	AdditionalPropsIgnore: "ignore",
+	AdditionalPropsReject: "reject",
+}
+
+func (p AdditionalPropsPolicy) String() string {
+	if name, ok := additionalPropsPolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("AdditionalPropsPolicy(%d)", int(p))
+}
+
+// ParseAdditionalPropsPolicy returns the policy named s: "spec", "ignore" or "reject". The empty
+// string is the spec policy.
+func ParseAdditionalPropsPolicy(s string) (AdditionalPropsPolicy, error) {
+	if s == "" {
+		return AdditionalPropsSpec, nil
+	}
+
+	for p, name := range additionalPropsPolicyNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown additionalProperties policy %q, must be one of spec, ignore or reject", s)
+}
+
+// WithAdditionalProps sets the policy for objects that do not set additionalProperties.
+func WithAdditionalProps(p AdditionalPropsPolicy) Option {
+	return func(r *Registry) {
+		r.additionalProps = p
+	}
+}
+
+// OptionalRepr decides how a field that is not required, or that is nullable, is represented.
+type OptionalRepr int
+
+const (
+	// OptionalWrapper uses a wrapper type that tells unset, null and set values apart.
+	OptionalWrapper OptionalRepr = iota
+	// OptionalPointer uses a pointer to the value. nil stands for both unset and null.
+	OptionalPointer
+	// OptionalValue uses the value itself. The zero value stands for both unset and null.
+	OptionalValue
+)
+
+var optionalReprNames = map[OptionalRepr]string{
+	OptionalWrapper: "wrapper",
+	OptionalPointer: "pointer",
+	OptionalValue:   "value",
+}
+
+func (o OptionalRepr) String() string {
+	if name, ok := optionalReprNames[o]; ok {
+		return name
+	}
+	return fmt.Sprintf("OptionalRepr(%d)", int(o))
+}
+
+// ParseOptionalRepr returns the representation named s: "wrapper", "pointer" or "value". The
+// empty string is the wrapper representation.
+func ParseOptionalRepr(s string) (OptionalRepr, error) {
+	if s == "" {
+		return OptionalWrapper, nil
+	}
+
+	for o, name := range optionalReprNames {
+		if name == s {
+			return o, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown optional representation %q, must be one of wrapper, pointer or value", s)
+}
+
+// WithOptional sets the representation of fields that do not set x-go-optional.
+func WithOptional(o OptionalRepr) Option {
+	return func(r *Registry) {
+		r.optional = o
+	}
+}
+
+// HoistPolicy decides which nested schemas become declarations of their own rather than staying
+// inline in the type that contains them. Top-level schemas, enums and tuples are always
+// declarations.
+type HoistPolicy int
+
+const (
+	// HoistObjects hoists nested objects that declare properties. Maps and arrays stay inline.
+	HoistObjects HoistPolicy = iota
+	// HoistAll hoists nested objects, maps and arrays.
+	HoistAll
+	// HoistNever keeps nested objects, maps and arrays inline.
+	HoistNever
+)
+
+var hoistPolicyNames = map[HoistPolicy]string{
+	HoistObjects: "objects",
+	HoistAll:     "always",
+	HoistNever:   "never",
+}
+
+func (p HoistPolicy) String() string {
+	if name, ok := hoistPolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("HoistPolicy(%d)", int(p))
+}
+
+// ParseHoistPolicy returns the policy named s: "objects", "always" or "never". The empty string is
+// the objects policy.
+func ParseHoistPolicy(s string) (HoistPolicy, error) {
+	if s == "" {
+		return HoistObjects, nil
+	}
+
+	for p, name := range hoistPolicyNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hoisting policy %q, must be one of objects, always or never", s)
+}
+
+// WithHoisting sets the policy for nested schemas.
+func WithHoisting(p HoistPolicy) Option {
+	return func(r *Registry) {
+		r.hoist = p
+	}
+}
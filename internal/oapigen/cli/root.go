@@ -17,8 +17,32 @@ func NewRootCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&cfg.In, "in", "", "Path to OpenAPI spec (YAML/JSON)")
-	cmd.Flags().StringVar(&cfg.Out, "out", "", "Output directory for generated code")
+	cmd.Flags().StringVar(&cfg.Out, "out", "", "Output file for generated code, or directory with --split")
 	cmd.Flags().StringVar(&cfg.Pkg, "package", "", "Go package name for generated code")
+	cmd.Flags().StringVar(&cfg.Optional, "optional", "wrapper",
+		"Representation of optional and nullable fields: wrapper, pointer or value")
+	cmd.Flags().BoolVar(&cfg.DefinedRefs, "defined-refs", false,
+		"Generate schemas that are only a $ref as defined types instead of type aliases")
+	cmd.Flags().StringVar(&cfg.AdditionalProps, "additional-properties", "spec",
+		"Policy for objects that do not set additionalProperties: spec, ignore or reject")
+	cmd.Flags().StringVar(&cfg.Hoist, "hoist", "objects",
+		"Nested schemas generated as named types: objects, always or never")
+	cmd.Flags().BoolVar(&cfg.Dedup, "dedup", false,
+		"Merge schemas with the same shape and constraints into one type")
+	cmd.Flags().StringSliceVar(&cfg.IncludeTags, "include-tag", nil,
+		"Only generate operations with one of these tags")
+	cmd.Flags().StringSliceVar(&cfg.ExcludeTags, "exclude-tag", nil,
+		"Do not generate operations with one of these tags")
+	cmd.Flags().StringSliceVar(&cfg.IncludeOperations, "include-operation", nil,
+		"Only generate operations with one of these operationIds")
+	cmd.Flags().StringSliceVar(&cfg.ExcludeOperations, "exclude-operation", nil,
+		"Do not generate operations with one of these operationIds")
+	cmd.Flags().StringSliceVar(&cfg.IncludePaths, "include-path", nil,
+		"Only generate operations whose path matches one of these globs, e.g. /users/**")
+	cmd.Flags().StringSliceVar(&cfg.ExcludePaths, "exclude-path", nil,
+		"Do not generate operations whose path matches one of these globs")
+	cmd.Flags().StringVar(&cfg.Split, "split", "",
+		"Write one file per declaration, tag or root into the --out directory")
 	cmd.MarkFlagRequired("in")
 	cmd.MarkFlagRequired("out")
 
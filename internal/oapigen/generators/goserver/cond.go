@@ -0,0 +1,208 @@
+package goserver
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maketaio/openapi/codegen/model"
+)
+
+// writeConditionalValidation emits the dependentRequired, dependentSchemas and if/then/else checks of
+// the struct or map addressed by sub.
+func writeConditionalValidation(buf *bytes.Buffer, sub string, typ *model.Type) {
+	// extras addresses the map holding undeclared properties, if any
+	extras := ""
+	if isMapShaped(typ) {
+		extras = sub
+		if strings.HasPrefix(sub, "*") {
+			extras = "(" + sub + ")"
+		}
+	} else if hasExtraProps(typ) {
+		extras = sub + ".AdditionalProperties"
+	}
+
+	for _, dep := range typ.DependentRequired {
+		if !writePresentIf(buf, sub, extras, typ, dep.Prop) {
+			continue
+		}
+
+		for _, name := range dep.Required {
+			action := fmt.Sprintf("issues = append(issues, validation.NewObjDependentRequiredIssue(path.Field(%q), %q))\n", name, dep.Prop)
+			writeMissingCheck(buf, sub, extras, typ, name, action)
+		}
+		buf.WriteString("}\n")
+	}
+
+	for _, dep := range typ.DependentSchemas {
+		if !writePresentIf(buf, sub, extras, typ, dep.Prop) {
+			continue
+		}
+
+		writeConditionChecks(buf, sub, extras, typ, dep.Cond, func(name string) string {
+			return fmt.Sprintf("issues = append(issues, validation.NewObjDependentSchemaIssue(path.Field(%q), %q))\n", name, dep.Prop)
+		})
+		buf.WriteString("}\n")
+	}
+
+	if typ.If == nil || (typ.Then == nil && typ.Else == nil) {
+		return
+	}
+
+	buf.WriteString("{\n")
+	buf.WriteString("ifMatched := true\n")
+	writeConditionChecks(buf, sub, extras, typ, typ.If, func(string) string {
+		return "ifMatched = false\n"
+	})
+
+	if typ.Then != nil {
+		buf.WriteString("if ifMatched {\n")
+		writeConditionChecks(buf, sub, extras, typ, typ.Then, func(name string) string {
+			return fmt.Sprintf("issues = append(issues, validation.NewObjIfThenIssue(path.Field(%q)))\n", name)
+		})
+		if typ.Else != nil {
+			buf.WriteString("} else {\n")
+		}
+	} else {
+		buf.WriteString("if !ifMatched {\n")
+	}
+
+	if typ.Else != nil {
+		writeConditionChecks(buf, sub, extras, typ, typ.Else, func(name string) string {
+			return fmt.Sprintf("issues = append(issues, validation.NewObjIfElseIssue(path.Field(%q)))\n", name)
+		})
+	}
+	buf.WriteString("}\n")
+	buf.WriteString("}\n")
+}
+
+// writeConditionChecks emits the action returned by failed for every property violating cond.
+func writeConditionChecks(buf *bytes.Buffer, sub, extras string, typ *model.Type, cond *model.Condition, failed func(name string) string) {
+	for _, name := range cond.Required {
+		writeMissingCheck(buf, sub, extras, typ, name, failed(name))
+	}
+
+	for _, pc := range cond.Props {
+		field, ok := findField(typ, pc.Name)
+		if !ok {
+			continue
+		}
+
+		sel := sub + "." + toTitle(field.Name)
+		action := failed(pc.Name)
+
+		if field.Required && !field.Type.Nullable {
+			fmt.Fprintf(buf, "if !(%s) {\n", valuesMatch(sel, pc.Values))
+			buf.WriteString(action)
+			buf.WriteString("}\n")
+			continue
+		}
+
+		writeGetIf(buf, sel, field)
+		fmt.Fprintf(buf, "if !(%s) {\n", valuesMatch("v", pc.Values))
+		buf.WriteString(action)
+		buf.WriteString("}\n")
+		buf.WriteString("}\n")
+
+		// Only wrapped fields can tell null apart from unset
+		if field.Type.Nullable && isWrapped(field) && !slices.Contains(pc.Values, nil) {
+			fmt.Fprintf(buf, "if %s.IsNull() {\n", sel)
+			buf.WriteString(action)
+			buf.WriteString("}\n")
+		}
+	}
+}
+
+// writePresentIf opens an if statement whose body runs when the property is present. It returns
+// false without writing anything if the property can never be present.
+func writePresentIf(buf *bytes.Buffer, sub, extras string, typ *model.Type, name string) bool {
+	field, ok := findField(typ, name)
+	if ok && field.Required {
+		buf.WriteString("{\n")
+		return true
+	}
+
+	if ok {
+		fmt.Fprintf(buf, "if %s {\n", presentExpr(sub+"."+toTitle(field.Name), field))
+		return true
+	}
+
+	if extras != "" {
+		fmt.Fprintf(buf, "if _, ok := %s[%q]; ok {\n", extras, name)
+		return true
+	}
+
+	return false
+}
+
+// writeMissingCheck emits action if the property is absent.
+func writeMissingCheck(buf *bytes.Buffer, sub, extras string, typ *model.Type, name string, action string) {
+	field, ok := findField(typ, name)
+	if ok && field.Required {
+		return
+	}
+
+	if ok {
+		fmt.Fprintf(buf, "if %s {\n", negate(presentExpr(sub+"."+toTitle(field.Name), field)))
+	} else if extras != "" {
+		fmt.Fprintf(buf, "if _, ok := %s[%q]; !ok {\n", extras, name)
+	} else {
+		// The property is not declared and cannot be held anywhere, so it is always missing
+		buf.WriteString(action)
+		return
+	}
+
+	buf.WriteString(action)
+	buf.WriteString("}\n")
+}
+
+// negate returns the negation of the boolean expression expr.
+func negate(expr string) string {
+	if strings.Count(expr, " != ") == 1 && !strings.ContainsAny(expr, "&|") {
+		return strings.Replace(expr, " != ", " == ", 1)
+	}
+	if strings.ContainsAny(expr, " !") {
+		return "!(" + expr + ")"
+	}
+	return "!" + expr
+}
+
+// valuesMatch returns an expression checking that sel equals one of the non-null values.
+func valuesMatch(sel string, values []any) string {
+	var parts []string
+	for _, v := range values {
+		switch x := v.(type) {
+		case string:
+			parts = append(parts, sel+" == "+strconv.Quote(x))
+		case int64:
+			parts = append(parts, sel+" == "+strconv.FormatInt(x, 10))
+		case float64:
+			parts = append(parts, sel+" == "+strconv.FormatFloat(x, 'g', -1, 64))
+		case bool:
+			parts = append(parts, sel+" == "+strconv.FormatBool(x))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "false"
+	}
+
+	return strings.Join(parts, " || ")
+}
+
+func findField(typ *model.Type, name string) (model.Field, bool) {
+	for _, field := range typ.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+
+	return model.Field{}, false
+}
+
+func hasConditions(typ *model.Type) bool {
+	return len(typ.DependentRequired) > 0 || len(typ.DependentSchemas) > 0 ||
+		(typ.If != nil && (typ.Then != nil || typ.Else != nil))
+}
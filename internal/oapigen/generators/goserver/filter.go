@@ -0,0 +1,123 @@
+package goserver
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/maketaio/openapi/codegen/model"
+)
+
+// opFilter selects the operations to generate from the include and exclude lists of Config.
+type opFilter struct {
+	includeTags, excludeTags   []string
+	includeOps, excludeOps     []string
+	includePaths, excludePaths []*regexp.Regexp
+}
+
+func newOpFilter(cfg *Config) (*opFilter, error) {
+	f := &opFilter{
+		includeTags: cfg.IncludeTags,
+		excludeTags: cfg.ExcludeTags,
+		includeOps:  cfg.IncludeOperations,
+		excludeOps:  cfg.ExcludeOperations,
+	}
+
+	var err error
+	if f.includePaths, err = compileGlobs(cfg.IncludePaths); err != nil {
+		return nil, err
+	}
+	if f.excludePaths, err = compileGlobs(cfg.ExcludePaths); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// active reports whether any filter is set.
+func (f *opFilter) active() bool {
+	return f.hasIncludes() || len(f.excludeTags) > 0 || len(f.excludeOps) > 0 || len(f.excludePaths) > 0
+}
+
+func (f *opFilter) hasIncludes() bool {
+	return len(f.includeTags) > 0 || len(f.includeOps) > 0 || len(f.includePaths) > 0
+}
+
+// keep reports whether op is generated. Without include filters every operation is included,
+// otherwise it must match one of them. An operation matching an exclude filter is never generated.
+func (f *opFilter) keep(op *model.Operation) bool {
+	if f.hasIncludes() && !f.matches(op, f.includeTags, f.includeOps, f.includePaths) {
+		return false
+	}
+
+	return !f.matches(op, f.excludeTags, f.excludeOps, f.excludePaths)
+}
+
+func (f *opFilter) matches(op *model.Operation, tags, ops []string, paths []*regexp.Regexp) bool {
+	for _, tag := range op.Tags {
+		if slices.Contains(tags, tag) {
+			return true
+		}
+	}
+
+	if slices.Contains(ops, op.ID) {
+		return true
+	}
+
+	for _, re := range paths {
+		if re.MatchString(op.Path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, glob := range globs {
+		re, err := compileGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// compileGlob turns a path glob into a regular expression. A * matches any characters within a
+// path segment, a ** any characters across segments, and a ? a single character other than /.
+// Everything else matches literally, including the braces of path parameters, e.g. /users/{id}.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	if glob == "" {
+		return nil, fmt.Errorf("path glob must not be empty")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for rest := glob; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "**"):
+			b.WriteString(".*")
+			rest = rest[2:]
+		case rest[0] == '*':
+			b.WriteString("[^/]*")
+			rest = rest[1:]
+		case rest[0] == '?':
+			b.WriteString("[^/]")
+			rest = rest[1:]
+		default:
+			// Quote everything up to the next wildcard at once, so multi-byte characters stay whole
+			end := strings.IndexAny(rest, "*?")
+			if end == -1 {
+				end = len(rest)
+			}
+			b.WriteString(regexp.QuoteMeta(rest[:end]))
+			rest = rest[end:]
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,48 @@
+package goserver
+
+import "testing"
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+	}{
+		{"/users", []string{"/users"}, []string{"/users/", "/users/1", "/api/users"}},
+		{"/users/*", []string{"/users/", "/users/{id}", "/users/1"}, []string{"/users", "/users/1/posts"}},
+		{"/users/**", []string{"/users/", "/users/1", "/users/{id}/posts/{post}"}, []string{"/users", "/usersx/1"}},
+		{"/users**", []string{"/users", "/users/1/posts", "/usersx"}, []string{"/user"}},
+		{"/**/posts", []string{"/users/1/posts", "//posts"}, []string{"/posts", "/users/1/posts/2"}},
+		{"/*/posts", []string{"/users/posts"}, []string{"/users/1/posts"}},
+		{"/v?/users", []string{"/v1/users", "/vé/users"}, []string{"/v/users", "/v10/users", "/v//users"}},
+		{"/users/{id}", []string{"/users/{id}"}, []string{"/users/1", "/users/id"}},
+		{"/files/a.b+c", []string{"/files/a.b+c"}, []string{"/files/axb+c", "/files/a.bbc"}},
+		{"/x/(a|b)[0]^$", []string{"/x/(a|b)[0]^$"}, []string{"/x/a", "/x/b0"}},
+		{`/x\y`, []string{`/x\y`}, []string{"/xy"}},
+		{"/café/*", []string{"/café/1"}, []string{"/cafe/1", "/caf\xc3/1"}},
+		{"***", []string{"/a/b"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.glob, func(t *testing.T) {
+			re, err := compileGlob(test.glob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range test.match {
+				if !re.MatchString(path) {
+					t.Errorf("%q does not match", path)
+				}
+			}
+			for _, path := range test.noMatch {
+				if re.MatchString(path) {
+					t.Errorf("%q matches", path)
+				}
+			}
+		})
+	}
+
+	if _, err := compileGlob(""); err == nil {
+		t.Error("empty glob was accepted")
+	}
+}
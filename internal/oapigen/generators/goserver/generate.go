@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/maketaio/openapi/codegen/model"
 	"github.com/maketaio/openapi/internal/util/set"
@@ -20,6 +21,48 @@ type Config struct {
 	In  string
 	Out string
 	Pkg string
+
+	// Optional is the default representation of fields that are not required or nullable:
+	// "wrapper" (the default) uses the fields wrappers, "pointer" a pointer and "value" the value
+	// itself. Fields can override it with x-go-optional.
+	Optional string
+
+	// DefinedRefs generates schemas that are only a $ref as defined types that forward the methods
+	// of their target, rather than as type aliases.
+	DefinedRefs bool
+
+	// AdditionalProps is the policy for objects with declared properties that do not set
+	// additionalProperties: "spec" (the default) keeps undeclared properties, "ignore" drops them
+	// and "reject" fails decoding with codec.CodeUnknownField.
+	AdditionalProps string
+
+	// Hoist is the policy for nested schemas: "objects" (the default) generates named types for
+	// nested objects with properties, "always" also for maps and arrays, and "never" keeps them
+	// inline. Hoisted types are named after their location, e.g. UserAddress, unless they set
+	// x-go-name.
+	Hoist string
+
+	// Dedup merges declarations with the same shape and constraints into one type, which the
+	// others become aliases of, or defined types if DefinedRefs is set.
+	Dedup bool
+
+	// Operations to generate. Without include filters all operations are generated, otherwise
+	// those matching any of them. Operations matching an exclude filter are left out. Paths are
+	// globs where * matches within a path segment and ** across segments. When any filter is set,
+	// schemas that the generated operations do not use are left out as well.
+	IncludeTags       []string
+	ExcludeTags       []string
+	IncludeOperations []string
+	ExcludeOperations []string
+	IncludePaths      []string
+	ExcludePaths      []string
+
+	// Split spreads the generated code over several files, in which case Out is a directory:
+	// "declaration" writes a file per declaration, "tag" a file per tag of the operations that use
+	// the declarations, and "root" a file per top-level schema or operation. The Server interface
+	// and NewHandler go to server.gen.go. Files ending in .gen.go generated by an earlier run that
+	// are not written again are removed. The default writes everything to the Out file.
+	Split string
 }
 
 func Generate(cfg *Config) error {
@@ -38,44 +81,144 @@ func Generate(cfg *Config) error {
 		return err
 	}
 
-	r := model.NewRegistry()
+	policy, err := model.ParseAdditionalPropsPolicy(cfg.AdditionalProps)
+	if err != nil {
+		return err
+	}
+
+	optional, err := model.ParseOptionalRepr(cfg.Optional)
+	if err != nil {
+		return err
+	}
+
+	hoist, err := model.ParseHoistPolicy(cfg.Hoist)
+	if err != nil {
+		return err
+	}
+
+	switch cfg.Split {
+	case splitNone, splitDeclaration, splitTag, splitRoot:
+	default:
+		return fmt.Errorf("unknown split strategy %q, must be one of declaration, tag or root", cfg.Split)
+	}
+
+	filter, err := newOpFilter(cfg)
+	if err != nil {
+		return err
+	}
+
+	r := model.NewRegistry(
+		model.WithAdditionalProps(policy),
+		model.WithOptional(optional),
+		model.WithHoisting(hoist),
+	)
 	if err := r.Collect(dm); err != nil {
 		return fmt.Errorf("failed to collect declarations: %w", err)
 	}
 
-	src, err := emit(r, cfg)
+	if filter.active() {
+		r.RetainOperations(filter.keep)
+		r.Prune()
+	}
+
+	if cfg.Dedup {
+		for _, m := range r.Dedup() {
+			fmt.Printf("Merged %s into %s\n", strings.Join(m.Duplicates, ", "), m.Canonical)
+		}
+	}
+
+	files, err := emit(r, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to generate code: %w", err)
 	}
 
-	formatted, err := format.Source(src)
-	if err != nil {
-		return fmt.Errorf("failed to format code: %w", err)
+	// Format everything first so that a failure does not leave a partial output behind
+	for name, src := range files {
+		formatted, err := format.Source(src)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", name, err)
+		}
+		files[name] = formatted
 	}
 
-	outDir := filepath.Dir(cfg.Out)
+	dir := outDir(cfg)
 
-	if err := os.MkdirAll(outDir, 0o755); err != nil {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(cfg.Out, formatted, 0o644); err != nil {
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), src, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Split == splitNone {
+		fmt.Printf("Successfully generated %s\n", cfg.Out)
+		return nil
+	}
+
+	if err := removeStale(dir, files); err != nil {
 		return err
 	}
 
-	fmt.Printf("Successfully generated %s\n", cfg.Out)
+	fmt.Printf("Successfully generated %d files in %s\n", len(files), dir)
 	return nil
 }
 
-func emit(r *model.Registry, cfg *Config) ([]byte, error) {
-	var buf bytes.Buffer
+// emit generates the source of each file of the output, keyed by file name.
+func emit(r *model.Registry, cfg *Config) (map[string][]byte, error) {
+	namer := newDeclNamer()
+	if err := namer.generate(r); err != nil {
+		return nil, err
+	}
 
-	imports := analyzeImports(r)
+	out := map[string][]byte{}
+	for _, f := range planFiles(r, cfg, namer) {
+		src, err := emitFile(r, cfg, namer, f)
+		if err != nil {
+			return nil, err
+		}
+		out[f.name] = src
+	}
 
-	namer := newDeclNamer()
-	namer.generate(r)
+	return out, nil
+}
+
+// emitFile generates the source of f, importing what its declarations and operations use.
+func emitFile(r *model.Registry, cfg *Config, namer *declNamer, f *genFile) ([]byte, error) {
+	var body bytes.Buffer
+
+	imports := set.NewSet[string]()
+
+	for _, decl := range f.decls {
+		imports.Merge(declImports(r, decl, cfg))
 
-	fmt.Fprintf(&buf, "// Code generated by oapigen; DO NOT EDIT.\n")
+		fmt.Fprintf(&body, "// %s is the generated type for schema %s\n", namer.nameFor(decl.ID), decl.Loc)
+
+		writeDecl(&body, namer, decl, decl.Type.Kind == model.TypeRef && !cfg.DefinedRefs)
+		writeForwardedMethods(&body, r, namer, decl, cfg)
+		writeEnum(&body, namer, decl)
+		writeMarshalUnmarshal(&body, r, namer, decl)
+		writeValidation(&body, r, namer, decl)
+		if err := writeSQLJSON(&body, namer, decl); err != nil {
+			return nil, err
+		}
+		writeYAML(&body, namer, decl)
+	}
+
+	for _, op := range f.ops {
+		if err := writeOperation(&body, r, namer, op, imports); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.server {
+		writeServer(&body, r, namer, imports)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", generatedHeader)
 	fmt.Fprintf(&buf, "package %s\n\n", packageName(cfg))
 	if imports.Len() > 0 {
 		fmt.Fprintf(&buf, "import (\n")
@@ -84,19 +227,117 @@ func emit(r *model.Registry, cfg *Config) ([]byte, error) {
 		}
 		fmt.Fprintf(&buf, ")\n\n")
 	}
+	buf.Write(body.Bytes())
 
-	r.Range(func(id string, decl *model.Declaration) bool {
-		fmt.Fprintf(&buf, "// %s is the generated type for schema %s\n", namer.nameFor(id), decl.Loc)
+	return buf.Bytes(), nil
+}
 
-		writeDecl(&buf, namer, decl)
-		writeEnum(&buf, namer, decl)
-		writeMarshalUnmarshal(&buf, namer, decl)
-		writeValidation(&buf, namer, decl)
+// writeForwardedMethods writes the methods of the target of a declaration that is only a $ref and
+// is generated as a defined type, which does not inherit the methods of its target. Each method
+// converts the receiver and calls the method of the referenced type.
+func writeForwardedMethods(buf *bytes.Buffer, r *model.Registry, namer *declNamer, decl *model.Declaration, cfg *Config) {
+	if decl.Type.Kind != model.TypeRef || !cfg.DefinedRefs {
+		return
+	}
 
-		return true
-	})
+	target, ok := resolveDecl(r, decl.Type.Ref)
+	if !ok {
+		return
+	}
 
-	return buf.Bytes(), nil
+	declName := namer.nameFor(decl.ID)
+	refName := namer.nameFor(decl.Type.Ref)
+
+	if target.Type.Kind == model.TypeTuple || (target.Type.Kind == model.TypeObject && !isMapShaped(target.Type)) {
+		fmt.Fprintf(buf, "func (o %s) MarshalJSON() ([]byte, error) {\n", declName)
+		fmt.Fprintf(buf, "return %s(o).MarshalJSON()\n", refName)
+		buf.WriteString("}\n\n")
+
+		fmt.Fprintf(buf, "func (o *%s) UnmarshalJSON(data []byte) error {\n", declName)
+		fmt.Fprintf(buf, "return (*%s)(o).UnmarshalJSON(data)\n", refName)
+		buf.WriteString("}\n\n")
+	}
+
+	if requiresValidation(r, target.Type) {
+		fmt.Fprintf(buf, "func (o *%s) Validate(path fields.Path) validation.Issues {\n", declName)
+		fmt.Fprintf(buf, "return (*%s)(o).Validate(path)\n", refName)
+		buf.WriteString("}\n\n")
+	}
+
+	if target.SQLJSON {
+		fmt.Fprintf(buf, "func (o *%s) Scan(src any) error {\n", declName)
+		fmt.Fprintf(buf, "return (*%s)(o).Scan(src)\n", refName)
+		buf.WriteString("}\n\n")
+
+		fmt.Fprintf(buf, "func (o %s) Value() (driver.Value, error) {\n", declName)
+		fmt.Fprintf(buf, "return %s(o).Value()\n", refName)
+		buf.WriteString("}\n\n")
+	}
+
+	if viaJSONForYAML(target.Type) {
+		fmt.Fprintf(buf, "func (o %s) MarshalYAML() (any, error) {\n", declName)
+		fmt.Fprintf(buf, "return %s(o).MarshalYAML()\n", refName)
+		buf.WriteString("}\n\n")
+	}
+
+	if viaJSONForYAML(target.Type) || hasYAMLNulls(target.Type) {
+		fmt.Fprintf(buf, "func (o *%s) UnmarshalYAML(node *yaml.Node) error {\n", declName)
+		fmt.Fprintf(buf, "return (*%s)(o).UnmarshalYAML(node)\n", refName)
+		buf.WriteString("}\n\n")
+	}
+}
+
+// resolveDecl follows declarations that are only a $ref and returns the declaration they end at.
+func resolveDecl(r *model.Registry, id string) (*model.Declaration, bool) {
+	for {
+		decl, ok := r.Get(id)
+		if !ok || decl.Type.Kind != model.TypeRef {
+			return decl, ok
+		}
+		id = decl.Type.Ref
+	}
+}
+
+// writeSQLJSON writes Scan and Value methods that store a declaration in a JSON database column.
+// Fields generated with the same name as one of the methods are reported, since Go does not allow
+// both.
+func writeSQLJSON(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) error {
+	if !decl.SQLJSON {
+		return nil
+	}
+
+	for _, field := range decl.Type.Fields {
+		if name := toTitle(field.Name); name == "Scan" || name == "Value" {
+			return fmt.Errorf("schema %s sets x-go-sql-json, but its property %q clashes with the %s method", decl.Loc, field.Name, name)
+		}
+	}
+
+	declName := namer.nameFor(decl.ID)
+	fmt.Fprintf(buf, "// Scan implements sql.Scanner by decoding a JSON column. NULL resets o to its zero value.\n")
+	fmt.Fprintf(buf, "func (o *%s) Scan(src any) error {\n", declName)
+	buf.WriteString("var data []byte\n")
+	buf.WriteString("switch v := src.(type) {\n")
+	buf.WriteString("case nil:\n")
+	buf.WriteString("case []byte:\n")
+	buf.WriteString("data = v\n")
+	buf.WriteString("case string:\n")
+	buf.WriteString("data = []byte(v)\n")
+	buf.WriteString("default:\n")
+	fmt.Fprintf(buf, "return fmt.Errorf(\"cannot scan %%T into %s\", src)\n", declName)
+	buf.WriteString("}\n")
+	fmt.Fprintf(buf, "*o = %s{}\n", declName)
+	buf.WriteString("if data == nil {\n")
+	buf.WriteString("return nil\n")
+	buf.WriteString("}\n")
+	buf.WriteString("return json.Unmarshal(data, o)\n")
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "// Value implements driver.Valuer by encoding o as JSON.\n")
+	fmt.Fprintf(buf, "func (o %s) Value() (driver.Value, error) {\n", declName)
+	buf.WriteString("return json.Marshal(o)\n")
+	buf.WriteString("}\n\n")
+
+	return nil
 }
 
 func writeDoc(buf *bytes.Buffer, doc []string) {
@@ -107,7 +348,8 @@ func writeDoc(buf *bytes.Buffer, doc []string) {
 	}
 }
 
-func writeDecl(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
+// writeDecl writes the type of a declaration, as a type alias if alias is set.
+func writeDecl(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration, alias bool) {
 	writeDoc(buf, decl.Doc)
 	if decl.Deprecated {
 		buf.WriteString("// Deprecated ")
@@ -115,6 +357,9 @@ func writeDecl(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
 	}
 	buf.WriteString("type ")
 	buf.WriteString(namer.nameFor(decl.ID))
+	if alias {
+		buf.WriteString(" =")
+	}
 	buf.WriteString(" ")
 	writeType(buf, namer, decl.Type)
 	buf.WriteString("\n")
@@ -129,13 +374,7 @@ func writeType(buf *bytes.Buffer, namer *declNamer, typ *model.Type) {
 	case model.TypeFloat64:
 		buf.WriteString("float64")
 	case model.TypeString:
-		if typ.Format == "date" || typ.Format == "date-time" {
-			buf.WriteString("time.Time")
-		} else if typ.Format == "binary" || typ.Format == "byte" {
-			buf.WriteString("[]byte")
-		} else {
-			buf.WriteString("string")
-		}
+		buf.WriteString(goStringType(typ))
 	case model.TypeBool:
 		buf.WriteString("bool")
 	case model.TypeRef:
@@ -147,12 +386,9 @@ func writeType(buf *bytes.Buffer, namer *declNamer, typ *model.Type) {
 		}
 		writeType(buf, namer, typ.Elem)
 	case model.TypeObject:
-		if typ.Elem != nil && len(typ.Fields) == 0 {
+		if isMapShaped(typ) {
 			buf.WriteString("map[string]")
-			if typ.Elem.Nullable {
-				buf.WriteString("*")
-			}
-			writeType(buf, namer, typ.Elem)
+			writeValueType(buf, namer, extraPropsType(typ))
 			return
 		}
 
@@ -167,145 +403,992 @@ func writeType(buf *bytes.Buffer, namer *declNamer, typ *model.Type) {
 
 			buf.WriteString(toTitle(field.Name))
 			buf.WriteString(" ")
-
-			if !field.Required && field.Type.Nullable {
-				buf.WriteString("fields.OptionalNullable[")
-				writeType(buf, namer, field.Type)
-				buf.WriteString("]")
-			} else if !field.Required {
-				buf.WriteString("fields.Optional[")
-				writeType(buf, namer, field.Type)
-				buf.WriteString("]")
-			} else if field.Type.Nullable {
-				buf.WriteString("fields.Nullable[")
-				writeType(buf, namer, field.Type)
-				buf.WriteString("]")
-			} else {
-				writeType(buf, namer, field.Type)
-			}
-
+			writeFieldType(buf, namer, field)
 			buf.WriteString(" `json:\"")
 			buf.WriteString(field.Name)
 			if !field.Required {
 				buf.WriteString(",omitzero")
 			}
+			buf.WriteString("\" yaml:\"")
+			buf.WriteString(field.Name)
+			if !field.Required {
+				buf.WriteString(",omitempty")
+			}
 			buf.WriteString("\"`\n")
 		}
 
-		if typ.Elem != nil {
+		if hasExtraProps(typ) {
 			buf.WriteString("AdditionalProperties map[string]")
+			writeValueType(buf, namer, extraPropsType(typ))
+			if extraPropsType(typ).Kind == model.TypeUnknown {
+				buf.WriteString(" `json:\"-\" yaml:\"-\"`\n")
+			} else {
+				buf.WriteString(" `json:\"-\" yaml:\",inline\"`\n")
+			}
+		}
+
+		buf.WriteString("}")
+	case model.TypeTuple:
+		buf.WriteString("struct {\n")
+		for _, field := range typ.Fields {
+			writeDoc(buf, field.Doc)
+
+			if field.Deprecated {
+				buf.WriteString("// Deprecated ")
+				buf.WriteString("\n")
+			}
+
+			buf.WriteString(tupleFieldName(field))
+			buf.WriteString(" ")
+			writeFieldType(buf, namer, field)
+			buf.WriteString("\n")
+		}
+
+		if typ.Elem != nil {
+			buf.WriteString("Rest []")
+			if typ.Elem.Nullable {
+				buf.WriteString("*")
+			}
 			writeType(buf, namer, typ.Elem)
-			buf.WriteString(" `json:\"-\"`\n")
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString("}")
+	case model.TypeUnknown:
+		buf.WriteString("json.RawMessage")
+	}
+}
+
+// writeFieldType writes the type of a struct or tuple field, wrapping it according to whether
+// the field is required and nullable, and to its optional representation.
+func writeFieldType(buf *bytes.Buffer, namer *declNamer, field model.Field) {
+	if !isWrapped(field) {
+		if (!field.Required || field.Type.Nullable) && field.Optional == model.OptionalPointer {
+			buf.WriteString("*")
+		}
+		writeType(buf, namer, field.Type)
+		return
+	}
+
+	if !field.Required && field.Type.Nullable {
+		buf.WriteString("fields.OptionalNullable[")
+		writeType(buf, namer, field.Type)
+		buf.WriteString("]")
+	} else if !field.Required {
+		buf.WriteString("fields.Optional[")
+		writeType(buf, namer, field.Type)
+		buf.WriteString("]")
+	} else if field.Type.Nullable {
+		buf.WriteString("fields.Nullable[")
+		writeType(buf, namer, field.Type)
+		buf.WriteString("]")
+	} else {
+		writeType(buf, namer, field.Type)
+	}
+}
+
+// writeValueType writes the type of a map or slice value, which is a pointer when nullable.
+func writeValueType(buf *bytes.Buffer, namer *declNamer, typ *model.Type) {
+	if typ.Nullable {
+		buf.WriteString("*")
+	}
+	writeType(buf, namer, typ)
+}
+
+// isWrapped reports whether a field uses one of the fields wrappers.
+func isWrapped(field model.Field) bool {
+	return (!field.Required || field.Type.Nullable) && field.Optional == model.OptionalWrapper
+}
+
+// writeGetIf opens an if statement whose body runs with v bound to the value of the field selected
+// by sel, when the field is not required or nullable and holds a value.
+func writeGetIf(buf *bytes.Buffer, sel string, field model.Field) {
+	switch field.Optional {
+	case model.OptionalPointer:
+		fmt.Fprintf(buf, "if %s != nil {\n", sel)
+		fmt.Fprintf(buf, "v := *%s\n", sel)
+	case model.OptionalValue:
+		fmt.Fprintf(buf, "if v := %s; %s {\n", sel, nonZero("v", field.Type))
+	default:
+		fmt.Fprintf(buf, "if v, ok := %s.Get(); ok {\n", sel)
+	}
+}
+
+// presentExpr returns an expression reporting whether the field selected by sel is present. Fields
+// that are not wrapped cannot tell null and unset apart, so null counts as absent for them.
+func presentExpr(sel string, field model.Field) string {
+	switch {
+	case field.Required && !field.Type.Nullable:
+		return "true"
+	case field.Optional == model.OptionalPointer:
+		return sel + " != nil"
+	case field.Optional == model.OptionalValue:
+		return nonZero(sel, field.Type)
+	}
+	return sel + ".IsPresent()"
+}
+
+// nonZero returns an expression reporting whether sub, of type typ, is not the zero value.
+func nonZero(sub string, typ *model.Type) string {
+	switch typ.Kind {
+	case model.TypeInt32, model.TypeInt64, model.TypeFloat64:
+		return sub + " != 0"
+	case model.TypeBool:
+		return sub
+	case model.TypeString:
+		switch goStringType(typ) {
+		case "time.Time":
+			return "!" + sub + ".IsZero()"
+		case "[]byte":
+			return "len(" + sub + ") != 0"
+		}
+		return sub + " != \"\""
+	case model.TypeArray, model.TypeUnknown:
+		return "len(" + sub + ") != 0"
+	case model.TypeObject:
+		if isMapShaped(typ) {
+			return "len(" + sub + ") != 0"
+		}
+	}
+	return "!fields.IsZero(" + sub + ")"
+}
+
+// needsIsZero reports whether nonZero uses fields.IsZero for typ.
+func needsIsZero(typ *model.Type) bool {
+	return strings.HasPrefix(nonZero("v", typ), "!fields.")
+}
+
+func tupleFieldName(field model.Field) string {
+	return "Item" + field.Name
+}
+
+func writeEnum(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
+	if len(decl.Type.Enum) == 0 {
+		return
+	}
+
+	buf.WriteString("const (")
+	for _, enum := range decl.Type.Enum {
+		writeDoc(buf, enum.Doc)
+
+		suffix := enum.Name
+		if suffix == "" {
+			if enum.Int32 != nil {
+				suffix = strconv.FormatInt(int64(*enum.Int32), 10)
+			} else if enum.Int64 != nil {
+				suffix = strconv.FormatInt(*enum.Int64, 10)
+			} else if enum.Float64 != nil {
+				formatted := strconv.FormatFloat(*enum.Float64, 'g', -1, 64)
+				formatted = strings.ReplaceAll(formatted, ".", "_")
+				formatted = strings.ReplaceAll(formatted, "-", "m")
+				suffix = formatted
+			} else if enum.Str != nil {
+				suffix = *enum.Str
+			} else if enum.Bool != nil {
+				suffix = strconv.FormatBool(*enum.Bool)
+			}
 		}
 
-		buf.WriteString("}")
-	case model.TypeUnknown:
-		buf.WriteString("json.RawMessage")
+		declName := namer.nameFor(decl.ID)
+		fmt.Fprintf(buf, "%s %s = %s\n", declName+toTitle(suffix), declName, enumLiteral(enum))
+	}
+	buf.WriteString(")\n")
+}
+
+// enumLiteral returns the Go literal of an enum value.
+func enumLiteral(enum model.EnumConst) string {
+	if enum.Int32 != nil {
+		return strconv.FormatInt(int64(*enum.Int32), 10)
+	} else if enum.Int64 != nil {
+		return strconv.FormatInt(*enum.Int64, 10)
+	} else if enum.Float64 != nil {
+		return strconv.FormatFloat(*enum.Float64, 'g', -1, 64)
+	} else if enum.Str != nil {
+		return strconv.Quote(*enum.Str)
+	} else if enum.Bool != nil {
+		return strconv.FormatBool(*enum.Bool)
+	}
+
+	return ""
+}
+
+func writeMarshalUnmarshal(buf *bytes.Buffer, r *model.Registry, namer *declNamer, decl *model.Declaration) {
+	if decl.Type.Kind == model.TypeTuple {
+		writeTupleMarshalUnmarshal(buf, r, namer, decl)
+		return
+	}
+
+	if decl.Type.Kind == model.TypeObject && !isMapShaped(decl.Type) {
+		writeObjectMarshalJSON(buf, r, namer, decl)
+		writeObjectUnmarshalJSON(buf, r, namer, decl)
+	}
+}
+
+// codecKind tells how generated code encodes and decodes a value with the codec package.
+type codecKind int
+
+const (
+	codecValue codecKind = iota // Through encoding/json
+	codecInt
+	codecFloat
+	codecBool
+	codecString
+	codecMarshaler // Through the generated MarshalJSON and UnmarshalJSON methods
+)
+
+// codecKindOf returns the codec kind of a non-nullable value of typ. Declarations that are only a
+// $ref are resolved, since they are either aliases or forward the methods of their target.
+func codecKindOf(r *model.Registry, typ *model.Type) codecKind {
+	if typ.Kind == model.TypeRef {
+		decl, ok := resolveDecl(r, typ.Ref)
+		if !ok {
+			return codecValue
+		}
+
+		if decl.Type.Kind == model.TypeTuple || (decl.Type.Kind == model.TypeObject && !isMapShaped(decl.Type)) {
+			return codecMarshaler
+		}
+
+		typ = decl.Type
+	}
+
+	switch typ.Kind {
+	case model.TypeInt32, model.TypeInt64:
+		return codecInt
+	case model.TypeFloat64:
+		return codecFloat
+	case model.TypeBool:
+		return codecBool
+	case model.TypeString:
+		if goStringType(typ) == "string" {
+			return codecString
+		}
+	}
+
+	return codecValue
+}
+
+// writeObjectMarshalJSON writes a MarshalJSON method that encodes the fields of a struct in schema
+// order, followed by its additional properties sorted by name so the output is deterministic.
+// Optional fields are omitted when unset, and additional properties named like a declared field are
+// skipped since the field takes precedence. Fields that are not wrapped are omitted when nil or
+// zero if optional, and written as null otherwise if nullable.
+func writeObjectMarshalJSON(buf *bytes.Buffer, r *model.Registry, namer *declNamer, decl *model.Declaration) {
+	typ := decl.Type
+
+	fmt.Fprintf(buf, "func (o %s) MarshalJSON() ([]byte, error) {\n", namer.nameFor(decl.ID))
+	buf.WriteString("var e codec.Encoder\n")
+	buf.WriteString("e.BeginObject()\n")
+	for _, field := range typ.Fields {
+		sel := "o." + toTitle(field.Name)
+		switch {
+		case field.Required && !field.Type.Nullable:
+			fmt.Fprintf(buf, "e.Key(%q)\n", field.Name)
+			writeEncodeValue(buf, r, sel, field.Type)
+		case !field.Required && (!field.Type.Nullable || !isWrapped(field)):
+			writeGetIf(buf, sel, field)
+			fmt.Fprintf(buf, "e.Key(%q)\n", field.Name)
+			writeEncodeValue(buf, r, "v", field.Type)
+			buf.WriteString("}\n")
+		default:
+			if !field.Required {
+				fmt.Fprintf(buf, "if %s.IsPresent() {\n", sel)
+			}
+			fmt.Fprintf(buf, "e.Key(%q)\n", field.Name)
+			writeGetIf(buf, sel, field)
+			writeEncodeValue(buf, r, "v", field.Type)
+			buf.WriteString("} else {\n")
+			buf.WriteString("e.Null()\n")
+			buf.WriteString("}\n")
+			if !field.Required {
+				buf.WriteString("}\n")
+			}
+		}
+	}
+
+	if hasExtraProps(typ) {
+		valueType := extraPropsType(typ)
+		buf.WriteString("for _, k := range slices.Sorted(maps.Keys(o.AdditionalProperties)) {\n")
+		if len(typ.Fields) > 0 {
+			names := make([]string, 0, len(typ.Fields))
+			for _, field := range typ.Fields {
+				names = append(names, strconv.Quote(field.Name))
+			}
+			fmt.Fprintf(buf, "switch k {\ncase %s:\ncontinue\n}\n", strings.Join(names, ", "))
+		}
+		buf.WriteString("v := o.AdditionalProperties[k]\n")
+		buf.WriteString("e.Key(k)\n")
+		if valueType.Nullable {
+			buf.WriteString("if v == nil {\n")
+			buf.WriteString("e.Null()\n")
+			buf.WriteString("} else {\n")
+			writeEncodeValue(buf, r, "*v", valueType)
+			buf.WriteString("}\n")
+		} else {
+			writeEncodeValue(buf, r, "v", valueType)
+		}
+		buf.WriteString("}\n")
+	}
+
+	buf.WriteString("e.EndObject()\n")
+	buf.WriteString("return e.Bytes()\n")
+	buf.WriteString("}\n\n")
+}
+
+// writeEncodeValue writes the statement encoding the non-nullable value sub of type typ.
+func writeEncodeValue(buf *bytes.Buffer, r *model.Registry, sub string, typ *model.Type) {
+	// Declared types need a conversion to the underlying type
+	convert := func(goType string, exact model.TypeKind) string {
+		if typ.Kind == exact {
+			return sub
+		}
+		return goType + "(" + sub + ")"
+	}
+
+	switch codecKindOf(r, typ) {
+	case codecInt:
+		fmt.Fprintf(buf, "e.Int(%s)\n", convert("int64", model.TypeInt64))
+	case codecFloat:
+		fmt.Fprintf(buf, "e.Float(%s)\n", convert("float64", model.TypeFloat64))
+	case codecBool:
+		fmt.Fprintf(buf, "e.Bool(%s)\n", convert("bool", model.TypeBool))
+	case codecString:
+		fmt.Fprintf(buf, "e.String(%s)\n", convert("string", model.TypeString))
+	case codecMarshaler:
+		fmt.Fprintf(buf, "e.Marshal(%s)\n", sub)
+	default:
+		fmt.Fprintf(buf, "e.Value(%s)\n", sub)
+	}
+}
+
+// writeObjectUnmarshalJSON writes an UnmarshalJSON method that decodes a struct in a single pass.
+// Properties are matched by their exact name, and properties that are not declared go to the
+// additional properties, are rejected if the type says so, or are skipped otherwise. A null value
+// leaves a field that is not nullable unset, and a null object leaves o unchanged as with
+// encoding/json.
+func writeObjectUnmarshalJSON(buf *bytes.Buffer, r *model.Registry, namer *declNamer, decl *model.Declaration) {
+	typ := decl.Type
+	declName := namer.nameFor(decl.ID)
+
+	fmt.Fprintf(buf, "func (o *%s) UnmarshalJSON(data []byte) error {\n", declName)
+	buf.WriteString("d := codec.NewDecoder(data)\n")
+	buf.WriteString("if d.Null() {\n")
+	buf.WriteString("return d.End()\n")
+	buf.WriteString("}\n")
+	fmt.Fprintf(buf, "*o = %s{}\n", declName)
+	buf.WriteString("err := d.Object(func(key string) error {\n")
+	buf.WriteString("switch key {\n")
+	for _, field := range typ.Fields {
+		fmt.Fprintf(buf, "case %q:\n", field.Name)
+		writeDecodeField(buf, r, namer, "o."+toTitle(field.Name), field)
+	}
+	buf.WriteString("}\n")
+
+	if hasExtraProps(typ) {
+		valueType := extraPropsType(typ)
+		buf.WriteString("var v ")
+		writeValueType(buf, namer, valueType)
+		buf.WriteString("\n")
+		if valueType.Nullable {
+			fmt.Fprintf(buf, "if err := codec.Any(d, &v); err != nil {\n")
+		} else {
+			fmt.Fprintf(buf, "if err := %s; err != nil {\n", decodeCall(r, "&v", valueType))
+		}
+		buf.WriteString("return err\n")
+		buf.WriteString("}\n")
+		buf.WriteString("if o.AdditionalProperties == nil {\n")
+		buf.WriteString("o.AdditionalProperties = map[string]")
+		writeValueType(buf, namer, valueType)
+		buf.WriteString("{}\n")
+		buf.WriteString("}\n")
+		buf.WriteString("o.AdditionalProperties[key] = v\n")
+		buf.WriteString("return nil\n")
+	} else if typ.RejectUnknown {
+		names := make([]string, 0, len(typ.Fields))
+		for _, field := range typ.Fields {
+			names = append(names, strconv.Quote(field.Name))
+		}
+		fmt.Fprintf(buf, "return d.UnknownField(%s)\n", strings.Join(names, ", "))
+	} else {
+		buf.WriteString("return d.Skip()\n")
+	}
+
+	buf.WriteString("})\n")
+	buf.WriteString("if err != nil {\n")
+	buf.WriteString("return err\n")
+	buf.WriteString("}\n")
+	buf.WriteString("return d.End()\n")
+	buf.WriteString("}\n\n")
+}
+
+// writeDecodeField writes the statements decoding the next value into the struct field or tuple
+// item sel, returning from the enclosing function. A null value leaves a field that is not nullable
+// unset.
+func writeDecodeField(buf *bytes.Buffer, r *model.Registry, namer *declNamer, sel string, field model.Field) {
+	buf.WriteString("if d.Null() {\n")
+	if field.Type.Nullable && isWrapped(field) {
+		fmt.Fprintf(buf, "%s.SetNull()\n", sel)
+	}
+	buf.WriteString("return nil\n")
+	buf.WriteString("}\n")
+
+	if (field.Required && !field.Type.Nullable) || field.Optional == model.OptionalValue {
+		fmt.Fprintf(buf, "return %s\n", decodeCall(r, "&"+sel, field.Type))
+		return
+	}
+
+	buf.WriteString("var v ")
+	writeType(buf, namer, field.Type)
+	buf.WriteString("\n")
+	fmt.Fprintf(buf, "if err := %s; err != nil {\n", decodeCall(r, "&v", field.Type))
+	buf.WriteString("return err\n")
+	buf.WriteString("}\n")
+	if field.Optional == model.OptionalPointer {
+		fmt.Fprintf(buf, "%s = &v\n", sel)
+	} else {
+		fmt.Fprintf(buf, "%s.Set(v)\n", sel)
+	}
+	buf.WriteString("return nil\n")
+}
+
+// decodeCall returns the expression decoding the next value into the non-nullable target of type
+// typ, given as a pointer.
+func decodeCall(r *model.Registry, target string, typ *model.Type) string {
+	switch codecKindOf(r, typ) {
+	case codecInt:
+		return "codec.Int(d, " + target + ")"
+	case codecFloat:
+		return "codec.Float(d, " + target + ")"
+	case codecBool:
+		return "codec.Bool(d, " + target + ")"
+	case codecString:
+		return "codec.String(d, " + target + ")"
+	case codecMarshaler:
+		return "codec.Unmarshal(d, " + target + ")"
+	}
+	return "codec.Any(d, " + target + ")"
+}
+
+// writeTupleMarshalUnmarshal emits positional JSON encoding for a tuple declaration. Optional
+// items are written up to the first absent one, and rest items only follow a complete prefix.
+func writeTupleMarshalUnmarshal(buf *bytes.Buffer, r *model.Registry, namer *declNamer, decl *model.Declaration) {
+	declName := namer.nameFor(decl.ID)
+	typ := decl.Type
+	required := requiredTupleItems(typ)
+
+	fmt.Fprintf(buf, "func (o *%s) UnmarshalJSON(data []byte) error {\n", declName)
+	buf.WriteString("d := codec.NewDecoder(data)\n")
+	buf.WriteString("if d.Null() {\n")
+	buf.WriteString("return d.End()\n")
+	buf.WriteString("}\n")
+	fmt.Fprintf(buf, "*o = %s{}\n", declName)
+	buf.WriteString("n := 0\n")
+	buf.WriteString("err := d.Array(func(i int) error {\n")
+	buf.WriteString("n++\n")
+	buf.WriteString("switch i {\n")
+	for i, field := range typ.Fields {
+		fmt.Fprintf(buf, "case %d:\n", i)
+		writeDecodeField(buf, r, namer, "o."+tupleFieldName(field), field)
+	}
+	buf.WriteString("}\n")
+	if typ.Elem == nil {
+		buf.WriteString("return &codec.Issue{\n")
+		buf.WriteString("Path: d.Path(),\n")
+		buf.WriteString("Code: codec.CodeUnknownField,\n")
+		fmt.Fprintf(buf, "Message: %q,\n", fmt.Sprintf("tuple must have at most %d items", len(typ.Fields)))
+		buf.WriteString("}\n")
+	} else {
+		buf.WriteString("var v ")
+		writeValueType(buf, namer, typ.Elem)
+		buf.WriteString("\n")
+		if typ.Elem.Nullable {
+			buf.WriteString("if err := codec.Any(d, &v); err != nil {\n")
+		} else {
+			fmt.Fprintf(buf, "if err := %s; err != nil {\n", decodeCall(r, "&v", typ.Elem))
+		}
+		buf.WriteString("return err\n")
+		buf.WriteString("}\n")
+		buf.WriteString("o.Rest = append(o.Rest, v)\n")
+		buf.WriteString("return nil\n")
+	}
+	buf.WriteString("})\n")
+	buf.WriteString("if err != nil {\n")
+	buf.WriteString("return err\n")
+	buf.WriteString("}\n")
+	if required > 0 {
+		fmt.Fprintf(buf, "if n < %d {\n", required)
+		buf.WriteString("return &codec.Issue{\n")
+		buf.WriteString("Path: fields.Path{}.Index(n),\n")
+		buf.WriteString("Code: codec.CodeMissingField,\n")
+		buf.WriteString("Message: \"tuple item \" + strconv.Itoa(n) + \" is required\",\n")
+		buf.WriteString("}\n")
+		buf.WriteString("}\n")
+	}
+	buf.WriteString("return d.End()\n")
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "func (o %s) MarshalJSON() ([]byte, error) {\n", declName)
+	if typ.Elem != nil {
+		fmt.Fprintf(buf, "items := make([]any, 0, %d+len(o.Rest))\n", len(typ.Fields))
+	} else {
+		fmt.Fprintf(buf, "items := make([]any, 0, %d)\n", len(typ.Fields))
+	}
+	for i, field := range typ.Fields {
+		if i >= required {
+			fmt.Fprintf(buf, "if !o.%s.IsPresent() {\n", tupleFieldName(field))
+			buf.WriteString("return json.Marshal(items)\n")
+			buf.WriteString("}\n")
+		}
+		fmt.Fprintf(buf, "items = append(items, o.%s)\n", tupleFieldName(field))
+	}
+	if typ.Elem != nil {
+		buf.WriteString("for _, v := range o.Rest {\n")
+		buf.WriteString("items = append(items, v)\n")
+		buf.WriteString("}\n")
+	}
+	buf.WriteString("return json.Marshal(items)\n")
+	buf.WriteString("}\n\n")
+}
+
+// requiredTupleItems returns the number of leading tuple items that must be present.
+func requiredTupleItems(typ *model.Type) int {
+	required := 0
+	for _, field := range typ.Fields {
+		if !field.Required {
+			break
+		}
+
+		required++
+	}
+
+	return required
+}
+
+func writeValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, decl *model.Declaration) {
+	// Aliases share the method of their target, defined types get it from writeForwardedMethods
+	if decl.Type.Kind == model.TypeRef || !requiresValidation(r, decl.Type) {
+		return
+	}
+
+	declName := namer.nameFor(decl.ID)
+	fmt.Fprintf(buf, "func (o *%s) Validate(path fields.Path) validation.Issues {\n", declName)
+	buf.WriteString("if o == nil { return nil }\n")
+	buf.WriteString("var issues validation.Issues\n")
+	if decl.Type.Kind == model.TypeTuple {
+		writeTupleValidationBody(buf, r, namer, decl.Type)
+	} else if decl.Type.Kind == model.TypeObject && !isMapShaped(decl.Type) {
+		writeValidationBody(buf, r, namer, "o", decl.Type)
+	} else {
+		writeValidationBody(buf, r, namer, "*o", decl.Type)
+	}
+	buf.WriteString("return issues\n")
+	buf.WriteString("}\n\n")
+}
+
+// writeTupleCount declares n as the number of items MarshalJSON writes for the tuple: the required
+// ones, the optional ones up to the first absent one, and rest items only after a complete prefix.
+func writeTupleCount(buf *bytes.Buffer, typ *model.Type) {
+	required := requiredTupleItems(typ)
+	fmt.Fprintf(buf, "n := %d\n", required)
+	for _, field := range typ.Fields[required:] {
+		fmt.Fprintf(buf, "if o.%s.IsPresent() {\n", tupleFieldName(field))
+		buf.WriteString("n++\n")
+	}
+	if typ.Elem != nil {
+		buf.WriteString("n += len(o.Rest)\n")
+	}
+	buf.WriteString(strings.Repeat("}\n", len(typ.Fields)-required))
+}
+
+// writeTupleValidationBody validates each tuple item under its own index in the path.
+func writeTupleValidationBody(buf *bytes.Buffer, r *model.Registry, namer *declNamer, typ *model.Type) {
+	for _, field := range typ.Fields {
+		writeFieldValidation(buf, r, namer, "o."+tupleFieldName(field), field, true)
+	}
+
+	if typ.Min != nil || typ.Max != nil {
+		writeTupleCount(buf, typ)
+	}
+
+	if typ.Max != nil {
+		fmt.Fprintf(buf, "if n > %d {\n", *typ.Max)
+		fmt.Fprintf(buf, "issues = append(issues, validation.NewArrMaxItemsIssue(path, %d))\n", *typ.Max)
+		buf.WriteString("}\n")
+	}
+
+	if typ.Min != nil {
+		fmt.Fprintf(buf, "if n < %d {\n", *typ.Min)
+		fmt.Fprintf(buf, "issues = append(issues, validation.NewArrMinItemsIssue(path, %d))\n", *typ.Min)
+		buf.WriteString("}\n")
+	}
+
+	if typ.Elem == nil {
+		return
+	}
+
+	if requiresValidation(r, typ.Elem) && !typ.Elem.Nullable {
+		buf.WriteString("for i, item := range o.Rest {\n")
+		fmt.Fprintf(buf, "path := path.Index(%d + i)\n", len(typ.Fields))
+		writeValidationBody(buf, r, namer, "item", typ.Elem)
+		buf.WriteString("}\n")
+	}
+}
+
+// writeContainsValidation counts the items matching the contains schema and checks the count against
+// minContains and maxContains. An item is converted to each type of the schema it can hold, and is
+// checked with the regular validation code, which appends to a shadowed issues slice so that only
+// the outcome is kept.
+func writeContainsValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, sub string, typ *model.Type) {
+	contains := typ.Contains
+	elem := typ.Elem
+
+	// checks holds the code matching an item against each type, which continues with the next item
+	// on a match. all is set if every item that is not null matches.
+	var checks bytes.Buffer
+	all := false
+	for _, ct := range contains.Types {
+		match := containsMatchOf(r, elem, ct.Type)
+		if match == containsNever {
+			continue
+		}
+
+		var body bytes.Buffer
+		writeValidationBody(&body, r, namer, "v", ct.Type)
+
+		if match == containsConvert && len(ct.Values) == 0 && body.Len() == 0 {
+			all = true
+			break
+		}
+
+		// Blocks opened for this type, closed at the end
+		blocks := 1
+		checks.WriteString("{\n")
+
+		if match == containsConvert {
+			var elemType, itemType bytes.Buffer
+			writeType(&elemType, namer, elem)
+			writeType(&itemType, namer, ct.Type)
+			if elemType.String() == itemType.String() {
+				checks.WriteString("v := item\n")
+			} else {
+				fmt.Fprintf(&checks, "v := %s(item)\n", itemType.String())
+			}
+		} else {
+			checks.WriteString("var v ")
+			writeType(&checks, namer, ct.Type)
+			checks.WriteString("\n")
+			if elem.Kind == model.TypeUnknown {
+				checks.WriteString("if err := json.Unmarshal(item, &v); err == nil {\n")
+			} else {
+				checks.WriteString("if data, err := json.Marshal(item); err == nil && json.Unmarshal(data, &v) == nil {\n")
+			}
+			blocks++
+		}
+
+		if len(ct.Values) > 0 {
+			fmt.Fprintf(&checks, "if %s {\n", valuesMatch("v", ct.Values))
+			blocks++
+		}
+
+		if body.Len() > 0 {
+			checks.WriteString("var issues validation.Issues\n")
+			checks.Write(body.Bytes())
+			checks.WriteString("if len(issues) == 0 {\n")
+			checks.WriteString("contains++\n")
+			checks.WriteString("continue\n")
+			checks.WriteString("}\n")
+		} else {
+			checks.WriteString("contains++\n")
+			checks.WriteString("continue\n")
+		}
+
+		checks.WriteString(strings.Repeat("}\n", blocks))
+	}
+
+	buf.WriteString("{\n")
+
+	switch {
+	case all && (!elem.Nullable || contains.Nullable):
+		fmt.Fprintf(buf, "contains := int64(len(%s))\n", sub)
+	case all:
+		buf.WriteString("contains := int64(0)\n")
+		fmt.Fprintf(buf, "for _, ptr := range %s {\n", sub)
+		buf.WriteString("if ptr != nil {\n")
+		buf.WriteString("contains++\n")
+		buf.WriteString("}\n")
+		buf.WriteString("}\n")
+	case elem.Nullable || elem.Kind == model.TypeUnknown:
+		buf.WriteString("contains := int64(0)\n")
+		if elem.Nullable {
+			fmt.Fprintf(buf, "for _, ptr := range %s {\n", sub)
+			buf.WriteString("if ptr == nil {\n")
+		} else {
+			fmt.Fprintf(buf, "for _, item := range %s {\n", sub)
+			buf.WriteString("if string(item) == \"null\" {\n")
+		}
+		if contains.Nullable {
+			buf.WriteString("contains++\n")
+		}
+		buf.WriteString("continue\n")
+		buf.WriteString("}\n")
+		if elem.Nullable {
+			buf.WriteString("item := *ptr\n")
+		}
+		buf.Write(checks.Bytes())
+		buf.WriteString("}\n")
+	case checks.Len() > 0:
+		buf.WriteString("contains := int64(0)\n")
+		fmt.Fprintf(buf, "for _, item := range %s {\n", sub)
+		buf.Write(checks.Bytes())
+		buf.WriteString("}\n")
+	default:
+		// No item can match
+		buf.WriteString("contains := int64(0)\n")
+	}
+
+	if contains.Min > 0 {
+		fmt.Fprintf(buf, "if contains < %d {\n", contains.Min)
+		fmt.Fprintf(buf, "issues = append(issues, validation.NewArrMinContainsIssue(path, %d, contains))\n", contains.Min)
+		buf.WriteString("}\n")
+	}
+
+	if contains.Max != nil {
+		fmt.Fprintf(buf, "if contains > %d {\n", *contains.Max)
+		fmt.Fprintf(buf, "issues = append(issues, validation.NewArrMaxContainsIssue(path, %d, contains))\n", *contains.Max)
+		buf.WriteString("}\n")
+	}
+
+	buf.WriteString("}\n")
+}
+
+// containsMatch tells how generated code gets a value of a contains type from an array item.
+type containsMatch int
+
+const (
+	containsNever   containsMatch = iota // The item is of another JSON type and never matches
+	containsConvert                      // With a Go conversion
+	containsJSON                         // By decoding the item, or its JSON encoding
+)
+
+// containsMatchOf returns how an item of type elem is checked against the contains type typ.
+// Integers are numbers too, while numbers are integers only if they have no fractional part, which
+// only decoding tells.
+func containsMatchOf(r *model.Registry, elem, typ *model.Type) containsMatch {
+	if elem.Kind == model.TypeUnknown {
+		return containsJSON
+	}
+
+	if elem.Kind == model.TypeRef && typ.Kind == model.TypeRef && elem.Ref == typ.Ref {
+		return containsConvert
+	}
+
+	resolve := func(t *model.Type) *model.Type {
+		if t.Kind == model.TypeRef {
+			if decl, ok := resolveDecl(r, t.Ref); ok {
+				return decl.Type
+			}
+		}
+		return t
+	}
+
+	a, b := resolve(elem), resolve(typ)
+	isInt := func(k model.TypeKind) bool { return k == model.TypeInt32 || k == model.TypeInt64 }
+
+	switch {
+	case isInt(a.Kind) && (isInt(b.Kind) || b.Kind == model.TypeFloat64):
+		return containsConvert
+	case a.Kind == model.TypeFloat64 && isInt(b.Kind):
+		return containsJSON
+	case a.Kind == model.TypeString && b.Kind == model.TypeString:
+		if goStringType(a) == "string" && goStringType(b) == "string" {
+			return containsConvert
+		}
+		return containsJSON
+	case a.Kind != b.Kind:
+		// Tuples are arrays too
+		if (a.Kind == model.TypeArray || a.Kind == model.TypeTuple) && (b.Kind == model.TypeArray || b.Kind == model.TypeTuple) {
+			return containsJSON
+		}
+		return containsNever
+	case a.Kind == model.TypeFloat64 || a.Kind == model.TypeBool:
+		return containsConvert
+	}
+
+	return containsJSON
+}
+
+// writeFieldValidation validates a struct field under its name, or a tuple item under its index, in
+// the path, unwrapping optional and nullable fields first.
+func writeFieldValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, sel string, field model.Field, tuple bool) {
+	if !requiresValidation(r, field.Type) {
+		return
+	}
+
+	// A type may require validation only through parts that cannot be checked, which would leave
+	// the block with unused variables.
+	var body bytes.Buffer
+	if field.Required && !field.Type.Nullable {
+		writeValidationBody(&body, r, namer, sel, field.Type)
+	} else {
+		writeValidationBody(&body, r, namer, "v", field.Type)
+	}
+	if body.Len() == 0 {
+		return
+	}
+
+	buf.WriteString("{\n")
+	if tuple {
+		fmt.Fprintf(buf, "path := path.Index(%s)\n", field.Name)
+	} else {
+		fmt.Fprintf(buf, "path := path.Field(%q)\n", field.Name)
+	}
+	if field.Required && !field.Type.Nullable {
+		buf.Write(body.Bytes())
+	} else {
+		writeGetIf(buf, sel, field)
+		buf.Write(body.Bytes())
+		buf.WriteString("}\n")
 	}
+	buf.WriteString("}\n")
 }
 
-func writeEnum(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
-	if len(decl.Type.Enum) == 0 {
-		return
+// writeExtraPropsValidation validates the names and values of properties held in a map, routing each
+// value to the schemas of the patterns its name matches, or to additionalProperties otherwise.
+func writeExtraPropsValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, sub string, typ *model.Type) {
+	valueType := extraPropsType(typ)
+	checksValue := func(t *model.Type) bool {
+		return requiresValidation(r, t) || (valueType.Kind == model.TypeUnknown && t.Kind != model.TypeUnknown)
 	}
 
-	buf.WriteString("const (")
-	for _, enum := range decl.Type.Enum {
-		writeDoc(buf, enum.Doc)
+	usesValue := false
+	for _, pp := range typ.PatternProps {
+		usesValue = usesValue || checksValue(pp.Type)
+	}
 
-		suffix := enum.Name
-		if suffix == "" {
-			if enum.Int32 != nil {
-				suffix = strconv.FormatInt(int64(*enum.Int32), 10)
-			} else if enum.Int64 != nil {
-				suffix = strconv.FormatInt(*enum.Int64, 10)
-			} else if enum.Float64 != nil {
-				formatted := strconv.FormatFloat(*enum.Float64, 'g', -1, 64)
-				formatted = strings.ReplaceAll(formatted, ".", "_")
-				formatted = strings.ReplaceAll(formatted, "-", "m")
-				suffix = formatted
-			} else if enum.Str != nil {
-				suffix = *enum.Str
-			} else if enum.Bool != nil {
-				suffix = strconv.FormatBool(*enum.Bool)
-			}
-		}
+	elemChecked := typ.Elem != nil && checksValue(typ.Elem)
+	usesValue = usesValue || elemChecked
+	rejectsUnmatched := typ.Elem == nil && len(typ.PatternProps) > 0
 
-		lit := ""
-		if enum.Int32 != nil {
-			lit = strconv.FormatInt(int64(*enum.Int32), 10)
-		} else if enum.Int64 != nil {
-			lit = strconv.FormatInt(*enum.Int64, 10)
-		} else if enum.Float64 != nil {
-			lit = strconv.FormatFloat(*enum.Float64, 'g', -1, 64)
-		} else if enum.Str != nil {
-			lit = strconv.Quote(*enum.Str)
-		} else if enum.Bool != nil {
-			lit = strconv.FormatBool(*enum.Bool)
-		}
+	if typ.PropNames == nil && !usesValue && !rejectsUnmatched {
+		return
+	}
 
-		declName := namer.nameFor(decl.ID)
-		fmt.Fprintf(buf, "%s %s = %s\n", declName+toTitle(suffix), declName, lit)
+	if usesValue {
+		fmt.Fprintf(buf, "for k, v := range %s {\n", sub)
+	} else {
+		fmt.Fprintf(buf, "for k := range %s {\n", sub)
 	}
-	buf.WriteString(")\n")
-}
+	buf.WriteString("path := path.Key(k)\n")
 
-func writeMarshalUnmarshal(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
-	if decl.Type.Kind == model.TypeObject && decl.Type.Elem != nil {
-		declName := namer.nameFor(decl.ID)
+	if typ.PropNames != nil {
+		writeKeyValidation(buf, r, namer, typ.PropNames)
+	}
 
-		fmt.Fprintf(buf, "func (o *%s) UnmarshalJSON(data []byte) error {\n", declName)
-		fmt.Fprintf(buf, "type alias %s\n", declName)
-		buf.WriteString("a := alias{}\n")
-		buf.WriteString("if err := json.Unmarshal(data, &a); err != nil {\n")
-		buf.WriteString("return err\n")
-		buf.WriteString("}\n")
-		fmt.Fprintf(buf, "*o = %s(a)\n", declName)
-		buf.WriteString("ap := map[string]")
-		writeType(buf, namer, decl.Type.Elem)
-		buf.WriteString("{}\n")
-		buf.WriteString("if err := json.Unmarshal(data, &ap); err != nil {\n")
-		buf.WriteString("return err\n")
-		buf.WriteString("}\n")
-		for _, field := range decl.Type.Fields {
-			fmt.Fprintf(buf, "delete(ap, %q)\n", field.Name)
+	if elemChecked && len(typ.PatternProps) > 0 {
+		buf.WriteString("matched := false\n")
+	}
+
+	for _, pp := range typ.PatternProps {
+		if !checksValue(pp.Type) && !elemChecked {
+			continue
+		}
+
+		fmt.Fprintf(buf, "if validation.MatchString(%q, k) {\n", pp.Pattern)
+		if elemChecked {
+			buf.WriteString("matched = true\n")
+		}
+		if checksValue(pp.Type) {
+			writeExtraValueValidation(buf, r, namer, valueType, pp.Type, pp.Pattern)
 		}
-		buf.WriteString("if len(ap) > 0 {\n")
-		buf.WriteString("o.AdditionalProperties = ap\n")
 		buf.WriteString("}\n")
-		buf.WriteString("return nil\n")
-		buf.WriteString("}\n\n")
+	}
 
-		fmt.Fprintf(buf, "func (o %s) MarshalJSON() ([]byte, error) {\n", declName)
-		fmt.Fprintf(buf, "o := make(map[string]any, len(o.AdditionalProperties)+%d)\n", len(decl.Type.Fields))
-		for _, field := range decl.Type.Fields {
-			fmt.Fprintf(buf, "o[%q] = o.%s\n", field.Name, field.Name)
+	if rejectsUnmatched {
+		buf.WriteString("if ")
+		for i, pp := range typ.PatternProps {
+			if i > 0 {
+				buf.WriteString(" && ")
+			}
+			fmt.Fprintf(buf, "!validation.MatchString(%q, k)", pp.Pattern)
 		}
-		buf.WriteString("for k, v := range o.AdditionalProperties {\n")
-		buf.WriteString("o[k] = v\n")
+		buf.WriteString(" {\n")
+		buf.WriteString("issues = append(issues, validation.NewObjPropNotAllowedIssue(path))\n")
 		buf.WriteString("}\n")
-		buf.WriteString("return json.Marshal(o)\n")
-		buf.WriteString("}\n\n")
+	}
+
+	if elemChecked {
+		if len(typ.PatternProps) > 0 {
+			buf.WriteString("if !matched {\n")
+			writeExtraValueValidation(buf, r, namer, valueType, typ.Elem, "")
+			buf.WriteString("}\n")
+		} else {
+			writeExtraValueValidation(buf, r, namer, valueType, typ.Elem, "")
+		}
+	}
 
+	buf.WriteString("}\n")
+}
+
+// writeExtraValueValidation validates the map value v against typ. When the map holds raw JSON
+// because its values may follow different schemas, v is decoded into typ first.
+func writeExtraValueValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, valueType, typ *model.Type, pattern string) {
+	if valueType.Kind != model.TypeUnknown || typ.Kind == model.TypeUnknown {
+		writeValueValidation(buf, r, namer, "v", typ)
 		return
 	}
+
+	buf.WriteString("var pv ")
+	writeValueType(buf, namer, typ)
+	buf.WriteString("\n")
+	buf.WriteString("if err := json.Unmarshal(v, &pv); err != nil {\n")
+	fmt.Fprintf(buf, "issues = append(issues, validation.NewObjPropSchemaIssue(path, %q))\n", pattern)
+	if requiresValidation(r, typ) {
+		buf.WriteString("} else {\n")
+		writeValueValidation(buf, r, namer, "pv", typ)
+	}
+	buf.WriteString("}\n")
 }
 
-func writeValidation(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
-	if !requiresValidation(decl.Type) {
+// writeValueValidation validates a map or slice value, which is a pointer when nullable.
+func writeValueValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, sub string, typ *model.Type) {
+	if !requiresValidation(r, typ) {
 		return
 	}
 
-	declName := namer.nameFor(decl.ID)
-	fmt.Fprintf(buf, "func (o *%s) Validate(path fields.Path) []*validation.Issue {\n", declName)
-	buf.WriteString("if o == nil { return nil }\n")
-	buf.WriteString("var issues []*validation.Issue\n")
-	writeValidationBody(buf, namer, "*o", decl.Type)
-	buf.WriteString("return issues\n")
-	buf.WriteString("}\n\n")
+	if typ.Nullable {
+		fmt.Fprintf(buf, "if %s != nil {\n", sub)
+		writeValidationBody(buf, r, namer, "*"+sub, typ)
+		buf.WriteString("}\n")
+		return
+	}
+
+	writeValidationBody(buf, r, namer, sub, typ)
+}
+
+// writeKeyValidation validates the property name k against a propertyNames schema.
+func writeKeyValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, typ *model.Type) {
+	if typ.Kind == model.TypeRef {
+		decl, ok := r.Get(typ.Ref)
+		if !ok {
+			return
+		}
+
+		typ = decl.Type
+	}
+
+	// Property names are plain strings whatever their format
+	keyType := *typ
+	keyType.Format = ""
+	writeValidationBody(buf, r, namer, "k", &keyType)
+
+	if typ.Format != "" {
+		fmt.Fprintf(buf, "if !validation.MatchFormat(%q, k) {\n", typ.Format)
+		fmt.Fprintf(buf, "issues = append(issues, validation.NewStrFormatIssue(path, %q))\n", typ.Format)
+		buf.WriteString("}\n")
+	}
 }
 
-func writeValidationBody(buf *bytes.Buffer, namer *declNamer, sub string, typ *model.Type) {
+func writeValidationBody(buf *bytes.Buffer, r *model.Registry, namer *declNamer, sub string, typ *model.Type) {
 	if typ.Kind == model.TypeString {
 		if typ.Max != nil {
 			fmt.Fprintf(buf, "if len(%s) > %d {\n", sub, *typ.Max)
@@ -325,6 +1408,12 @@ func writeValidationBody(buf *bytes.Buffer, namer *declNamer, sub string, typ *m
 			buf.WriteString("}\n")
 		}
 
+		if typ.Pattern != "" && goStringType(typ) == "string" {
+			fmt.Fprintf(buf, "if !validation.MatchString(%q, %s) {\n", typ.Pattern, sub)
+			fmt.Fprintf(buf, "issues = append(issues, validation.NewStrPatternIssue(path, %q))\n", typ.Pattern)
+			buf.WriteString("}\n")
+		}
+
 		return
 	}
 
@@ -409,16 +1498,55 @@ func writeValidationBody(buf *bytes.Buffer, namer *declNamer, sub string, typ *m
 			buf.WriteString("}\n")
 		}
 
-		if typ.Len != nil && *typ.Len != 0 {
-			buf.WriteString("for i, item := range %s {\n")
-			buf.WriteString("path = path.Field(strconv.Itoa(i))\n")
-			writeValidationBody(buf, namer, "item", typ.Elem)
+		if typ.UniqueItems {
+			finder := "FindDuplicatesJSON"
+			if isComparable(r, typ.Elem) {
+				finder = "FindDuplicates"
+			}
+
+			fmt.Fprintf(buf, "for _, d := range validation.%s(%s) {\n", finder, sub)
+			buf.WriteString("issues = append(issues, validation.NewArrUniqueItemsIssue(path, d.First, d.Dup))\n")
+			buf.WriteString("}\n")
+		}
+
+		if typ.Contains != nil {
+			writeContainsValidation(buf, r, namer, sub, typ)
+		}
+
+		var body bytes.Buffer
+		writeValidationBody(&body, r, namer, "item", typ.Elem)
+		if body.Len() > 0 {
+			if typ.Elem.Nullable {
+				fmt.Fprintf(buf, "for i, ptr := range %s {\n", sub)
+				buf.WriteString("if ptr == nil {\n")
+				buf.WriteString("continue\n")
+				buf.WriteString("}\n")
+				buf.WriteString("item := *ptr\n")
+			} else {
+				fmt.Fprintf(buf, "for i, item := range %s {\n", sub)
+			}
+			buf.WriteString("path := path.Index(i)\n")
+			buf.Write(body.Bytes())
 			buf.WriteString("}\n")
 		}
 
 		return
 	}
 
+	if typ.Kind == model.TypeObject && !isMapShaped(typ) {
+		for _, field := range typ.Fields {
+			writeFieldValidation(buf, r, namer, sub+"."+toTitle(field.Name), field, false)
+		}
+
+		if hasExtraProps(typ) {
+			writeExtraPropsValidation(buf, r, namer, sub+".AdditionalProperties", typ)
+		}
+
+		writeConditionalValidation(buf, sub, typ)
+
+		return
+	}
+
 	if typ.Kind == model.TypeObject {
 		if typ.Max != nil {
 			fmt.Fprintf(buf, "if len(%s) > %d {", sub, *typ.Max)
@@ -438,16 +1566,18 @@ func writeValidationBody(buf *bytes.Buffer, namer *declNamer, sub string, typ *m
 			buf.WriteString("}\n")
 		}
 
-		if typ.Len != nil && *typ.Len != 0 {
-			for _, field := range typ.Fields {
-				writeValidationBody(buf, namer, sub+"."+field.Name, field.Type)
-			}
-		}
+		writeExtraPropsValidation(buf, r, namer, sub, typ)
+		writeConditionalValidation(buf, sub, typ)
+
+		return
 	}
 
 	if typ.Kind == model.TypeRef {
-		if requiresValidation(typ) {
-			fmt.Fprintf(buf, "errors = append(errors, %s.Validate(path))\n", sub)
+		if requiresValidation(r, typ) {
+			if strings.HasPrefix(sub, "*") {
+				sub = "(" + sub + ")"
+			}
+			fmt.Fprintf(buf, "issues = append(issues, %s.Validate(path)...)\n", sub)
 		}
 
 		return
@@ -459,7 +1589,7 @@ func packageName(cfg *Config) string {
 		return cfg.Pkg
 	}
 
-	return filepath.Base(filepath.Dir(cfg.Out))
+	return filepath.Base(outDir(cfg))
 }
 
 func toTitle(s string) string {
@@ -470,21 +1600,73 @@ func toTitle(s string) string {
 	return cases.Title(language.English, cases.NoLower).String(s)
 }
 
-func analyzeImports(r *model.Registry) set.Set[string] {
+// toIdent turns s into an exported Go identifier by dropping any characters that are not letters
+// or digits and title-casing the words in between, e.g. "x-rate-limit" becomes "XRateLimit".
+func toIdent(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteString(toTitle(w))
+	}
+	return b.String()
+}
+
+// declImports returns the imports used by the code generated for decl.
+func declImports(r *model.Registry, decl *model.Declaration, cfg *Config) set.Set[string] {
 	imports := set.NewSet[string]()
 
-	r.Range(func(id string, m *model.Declaration) bool {
-		imports.Merge(doAnalyzeImports(m.Type))
-		return true
-	})
+	// Aliases only name their target, defined types forward its methods, see writeForwardedMethods
+	if decl.Type.Kind == model.TypeRef {
+		target, ok := resolveDecl(r, decl.Type.Ref)
+		if !ok || !cfg.DefinedRefs {
+			return imports
+		}
+
+		if requiresValidation(r, target.Type) {
+			imports.Add("github.com/maketaio/openapi/runtime/validation")
+			imports.Add("github.com/maketaio/openapi/runtime/fields")
+		}
+		if target.SQLJSON {
+			imports.Add("database/sql/driver")
+		}
+		if viaJSONForYAML(target.Type) || hasYAMLNulls(target.Type) {
+			imports.Add("go.yaml.in/yaml/v4")
+		}
+		return imports
+	}
+
+	imports.Merge(doAnalyzeImports(r, decl.Type))
+
+	if viaJSONForYAML(decl.Type) {
+		imports.Add("github.com/maketaio/openapi/runtime/codec")
+		imports.Add("go.yaml.in/yaml/v4")
+	} else if hasYAMLNulls(decl.Type) {
+		imports.Add("github.com/maketaio/openapi/runtime/fields")
+		imports.Add("go.yaml.in/yaml/v4")
+	}
+	if decl.Type.Kind == model.TypeObject && !isMapShaped(decl.Type) {
+		imports.Add("github.com/maketaio/openapi/runtime/codec")
+		if hasExtraProps(decl.Type) {
+			imports.Add("maps")
+			imports.Add("slices")
+		}
+	}
+	if decl.SQLJSON {
+		imports.Add("database/sql/driver")
+		imports.Add("encoding/json")
+		imports.Add("fmt")
+	}
 
 	return imports
 }
 
-func doAnalyzeImports(typ *model.Type) set.Set[string] {
+func doAnalyzeImports(r *model.Registry, typ *model.Type) set.Set[string] {
 	imports := set.NewSet[string]()
 
-	if requiresValidation(typ) {
+	if requiresValidation(r, typ) {
 		imports.Add("github.com/maketaio/openapi/runtime/validation")
 		imports.Add("github.com/maketaio/openapi/runtime/fields")
 
@@ -501,27 +1683,80 @@ func doAnalyzeImports(typ *model.Type) set.Set[string] {
 	}
 
 	if typ.Kind == model.TypeArray {
-		return doAnalyzeImports(typ.Elem)
+		imports.Merge(doAnalyzeImports(r, typ.Elem))
+		if typ.Contains != nil {
+			for _, ct := range typ.Contains.Types {
+				imports.Merge(doAnalyzeImports(r, ct.Type))
+				if containsMatchOf(r, typ.Elem, ct.Type) == containsJSON {
+					imports.Add("encoding/json")
+				}
+			}
+		}
+		return imports
 	}
 
-	if typ.Kind == model.TypeObject {
+	if typ.Kind == model.TypeTuple {
+		imports.Add("encoding/json")
+		imports.Add("github.com/maketaio/openapi/runtime/codec")
+
+		if requiredTupleItems(typ) > 0 {
+			imports.Add("github.com/maketaio/openapi/runtime/fields")
+			imports.Add("strconv")
+		}
+
 		for _, field := range typ.Fields {
 			if field.Type.Nullable || !field.Required {
 				imports.Add("github.com/maketaio/openapi/runtime/fields")
 			}
 
-			imports.Merge(doAnalyzeImports(field.Type))
+			imports.Merge(doAnalyzeImports(r, field.Type))
+		}
+
+		if typ.Elem != nil {
+			imports.Merge(doAnalyzeImports(r, typ.Elem))
+		}
+
+		return imports
+	}
+
+	if typ.Kind == model.TypeObject {
+		for _, field := range typ.Fields {
+			if isWrapped(field) {
+				imports.Add("github.com/maketaio/openapi/runtime/fields")
+			}
+
+			if (field.Type.Nullable || !field.Required) && field.Optional == model.OptionalValue && needsIsZero(field.Type) {
+				imports.Add("github.com/maketaio/openapi/runtime/fields")
+			}
+
+			imports.Merge(doAnalyzeImports(r, field.Type))
 		}
 
 		if typ.Elem != nil {
-			imports.Merge(doAnalyzeImports(typ.Elem))
+			imports.Merge(doAnalyzeImports(r, typ.Elem))
+		}
+
+		for _, pp := range typ.PatternProps {
+			imports.Merge(doAnalyzeImports(r, pp.Type))
+		}
+
+		if hasExtraProps(typ) && extraPropsType(typ).Kind == model.TypeUnknown {
+			imports.Add("encoding/json")
 		}
 	}
 
 	return imports
 }
 
-func requiresValidation(typ *model.Type) bool {
+// requiresValidation reports whether values of typ have constraints to check. References count when
+// the declaration they point to does, so that the enclosing type validates them as well.
+func requiresValidation(r *model.Registry, typ *model.Type) bool {
+	return doRequiresValidation(r, typ, set.NewSet[string]())
+}
+
+// doRequiresValidation implements requiresValidation. Declarations already in seen are being
+// checked further up, which keeps recursive schemas from looping.
+func doRequiresValidation(r *model.Registry, typ *model.Type, seen set.Set[string]) bool {
 	if typ.Kind == model.TypeInt32 || typ.Kind == model.TypeInt64 {
 		return typ.Max != nil || typ.Min != nil || typ.MultipleOf != nil
 	}
@@ -535,7 +1770,18 @@ func requiresValidation(typ *model.Type) bool {
 	}
 
 	if typ.Kind == model.TypeArray {
-		return typ.Len != nil || typ.Max != nil || typ.Min != nil || requiresValidation(typ.Elem)
+		return typ.Len != nil || typ.Max != nil || typ.Min != nil || typ.UniqueItems || typ.Contains != nil ||
+			doRequiresValidation(r, typ.Elem, seen)
+	}
+
+	if typ.Kind == model.TypeTuple {
+		for _, field := range typ.Fields {
+			if doRequiresValidation(r, field.Type, seen) {
+				return true
+			}
+		}
+
+		return typ.Max != nil || typ.Min != nil || (typ.Elem != nil && doRequiresValidation(r, typ.Elem, seen))
 	}
 
 	if typ.Kind == model.TypeObject {
@@ -543,20 +1789,118 @@ func requiresValidation(typ *model.Type) bool {
 			return true
 		}
 
-		for _, field := range typ.Fields {
+		if typ.PropNames != nil || len(typ.PatternProps) > 0 || hasConditions(typ) {
+			return true
+		}
 
-			if requiresValidation(field.Type) {
+		for _, field := range typ.Fields {
+			if doRequiresValidation(r, field.Type, seen) {
 				return true
 			}
 		}
 
 		if typ.Elem != nil {
-			return requiresValidation(typ.Elem)
+			return doRequiresValidation(r, typ.Elem, seen)
+		}
+
+		return false
+	}
+
+	if typ.Kind == model.TypeRef {
+		decl, ok := r.Get(typ.Ref)
+		if !ok || seen.Has(typ.Ref) {
+			return false
+		}
+
+		seen.Add(typ.Ref)
+		return doRequiresValidation(r, decl.Type, seen)
+	}
+
+	return false
+}
+
+// hasExtraProps reports whether an object holds properties beyond its declared fields.
+func hasExtraProps(typ *model.Type) bool {
+	return typ.Elem != nil || len(typ.PatternProps) > 0
+}
+
+// isMapShaped reports whether an object is generated as a plain map rather than a struct.
+func isMapShaped(typ *model.Type) bool {
+	return typ.Kind == model.TypeObject && len(typ.Fields) == 0 && hasExtraProps(typ)
+}
+
+// extraPropsType returns the value type of the map holding an object's extra properties. When
+// pattern properties and additionalProperties disagree on the value type, values are kept as raw
+// JSON and decoded during validation.
+func extraPropsType(typ *model.Type) *model.Type {
+	var candidates []*model.Type
+	for _, pp := range typ.PatternProps {
+		candidates = append(candidates, pp.Type)
+	}
+
+	if typ.Elem != nil {
+		candidates = append(candidates, typ.Elem)
+	}
+
+	for _, c := range candidates[1:] {
+		if !sameGoType(candidates[0], c) {
+			return &model.Type{Kind: model.TypeUnknown}
 		}
+	}
+
+	return candidates[0]
+}
+
+// sameGoType reports whether a and b are generated as the same Go type. Inline objects and tuples
+// are never considered the same.
+func sameGoType(a, b *model.Type) bool {
+	if a.Kind != b.Kind || a.Nullable != b.Nullable {
+		return false
+	}
 
+	switch a.Kind {
+	case model.TypeRef:
+		return a.Ref == b.Ref
+	case model.TypeString:
+		return goStringType(a) == goStringType(b)
+	case model.TypeArray:
+		return sameGoType(a.Elem, b.Elem)
+	case model.TypeObject, model.TypeTuple:
+		return a == b
+	}
+
+	return true
+}
+
+// goStringType returns the Go type a string schema is generated as, which depends on its format.
+func goStringType(typ *model.Type) string {
+	switch typ.Format {
+	case "date", "date-time":
+		return "time.Time"
+	case "binary", "byte":
+		return "[]byte"
+	}
+
+	return "string"
+}
+
+// isComparable reports whether values of typ can be compared with == in generated code with the
+// same outcome as comparing their JSON representations.
+func isComparable(r *model.Registry, typ *model.Type) bool {
+	if typ.Nullable {
 		return false
 	}
 
+	switch typ.Kind {
+	case model.TypeInt32, model.TypeInt64, model.TypeFloat64, model.TypeBool:
+		return true
+	case model.TypeString:
+		return typ.Format != "date" && typ.Format != "date-time" && typ.Format != "binary" && typ.Format != "byte"
+	case model.TypeRef:
+		decl, ok := r.Get(typ.Ref)
+		return ok && isComparable(r, decl.Type)
+	}
+
 	return false
 }
 
@@ -572,26 +1916,36 @@ func newDeclNamer() *declNamer {
 	}
 }
 
-func (n *declNamer) generate(r *model.Registry) {
-	// Generate names for top level declarations first
-	r.Range(func(id string, decl *model.Declaration) bool {
-		if !decl.Loc.IsTopLevel() {
-			return false
+func (n *declNamer) generate(r *model.Registry) error {
+	// The server code uses fixed names, so they are taken before any declaration is named
+	for _, name := range serverNames(r) {
+		if n.reserve(name) != name {
+			return fmt.Errorf("name %s is generated more than once for the operations", name)
 		}
+	}
 
-		n.names[decl.ID] = toTitle(decl.Loc.Root)
-		n.counter[n.names[decl.ID]] = 0
+	// Then names set with x-go-name, followed by the names of top level declarations
+	r.Range(func(id string, decl *model.Declaration) bool {
+		if decl.Name != "" {
+			n.names[decl.ID] = n.reserve(decl.Name)
+		}
+		return true
+	})
 
+	r.Range(func(id string, decl *model.Declaration) bool {
+		if _, named := n.names[decl.ID]; !named && decl.Loc.IsTopLevel() && decl.Loc.Op == nil {
+			n.names[decl.ID] = n.reserve(toTitle(decl.Loc.Root))
+		}
 		return true
 	})
 
 	// Generate names for nested declarations
 	r.Range(func(id string, decl *model.Declaration) bool {
-		if decl.Loc.IsTopLevel() {
-			return false
+		if _, named := n.names[decl.ID]; named {
+			return true
 		}
 
-		baseName := toTitle(decl.Loc.Root)
+		baseName := rootName(decl.Loc)
 		for _, seg := range decl.Loc.Path {
 			switch seg.Kind {
 			case model.SegmentProperty:
@@ -600,25 +1954,63 @@ func (n *declNamer) generate(r *model.Registry) {
 				baseName += "AdditionalProperty"
 			case model.SegmentItems:
 				baseName += "Item"
+			case model.SegmentPrefixItem:
+				baseName += "Item" + seg.Name
+			case model.SegmentPatternProperty:
+				baseName += "PatternProperty"
+			case model.SegmentPropertyNames:
+				baseName += "PropertyName"
+			case model.SegmentContains:
+				baseName += "Contains"
 			}
 		}
 
-		name := baseName
-		for {
-			count, found := n.counter[baseName]
-			if !found {
-				break
-			}
+		n.names[decl.ID] = n.reserve(baseName)
+
+		return true
+	})
+
+	return nil
+}
 
+// reserve returns baseName, or baseName followed by a number if it is already taken, and marks the
+// result as taken.
+func (n *declNamer) reserve(baseName string) string {
+	name := baseName
+	if count, found := n.counter[baseName]; found {
+		for {
 			count++
-			n.counter[baseName] = count
 			name = baseName + strconv.Itoa(count)
+			if _, taken := n.counter[name]; !taken {
+				break
+			}
 		}
 
-		n.names[decl.ID] = name
+		n.counter[baseName] = count
+	}
 
-		return true
-	})
+	n.counter[name] = 0
+	return name
+}
+
+// rootName returns the name for the root of a location. Schemas defined in operations are named
+// after the operation and the part they were found in, e.g. CreateUserRequestBody,
+// CreateUser201Response or CreateUser201XRateLimitHeader.
+func rootName(l model.Location) string {
+	if l.Op == nil {
+		return toTitle(l.Root)
+	}
+
+	name := toIdent(l.Op.OperationID)
+	switch l.Op.Part {
+	case model.OpRequestBody:
+		name += "RequestBody"
+	case model.OpResponseBody:
+		name += toTitle(l.Op.Status) + "Response"
+	case model.OpResponseHeader:
+		name += toTitle(l.Op.Status) + toIdent(l.Op.Header) + "Header"
+	}
+	return name
 }
 
 func (n *declNamer) nameFor(id string) string {
@@ -0,0 +1,289 @@
+package goserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maketaio/openapi/internal/oapigen/generators/goserver/testdata"
+	"github.com/maketaio/openapi/runtime/codec"
+	"github.com/maketaio/openapi/runtime/fields"
+	"github.com/maketaio/openapi/runtime/validation"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestUnmarshalNullObject(t *testing.T) {
+	u := testdata.User{Id: 1, Name: "Ada"}
+	if err := json.Unmarshal([]byte("null"), &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Id != 1 || u.Name != "Ada" {
+		t.Errorf("null changed the user to %+v", u)
+	}
+
+	var req struct {
+		User *testdata.User `json:"user"`
+	}
+	if err := json.Unmarshal([]byte(`{"user": null}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.User != nil {
+		t.Errorf("null user decoded as %+v", req.User)
+	}
+}
+
+func TestAdditionalPropertiesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"declared only", `{"plan":"free"}`},
+		{"null field", `{"plan":"free","note":null}`},
+		{"extras sorted", `{"plan":"pro","note":"x","cpu":4,"disk":100,"memory":16}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var q testdata.Quota
+			if err := json.Unmarshal([]byte(test.input), &q); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.input {
+				t.Errorf("got %s, want %s", got, test.input)
+			}
+		})
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		value any
+	}{
+		{"tuple", "- 13.4\n- 52.5\n", &testdata.Point{}},
+		{"raw extras", "theme: dark\nbeta: true\nlimits:\n    cpu: 2\nowner: \"42\"\n", &testdata.Settings{}},
+		{"nulls", "id: 1\nname: Ada\nmetadata: null\n", &testdata.User{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := yaml.Unmarshal([]byte(test.input), test.value); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := yaml.Marshal(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.input {
+				t.Errorf("got %q, want %q", got, test.input)
+			}
+		})
+	}
+}
+
+func TestUnmarshalYAMLTupleErrors(t *testing.T) {
+	for _, input := range []string{"[1]", "[1, 2, 3]", "{x: 1}"} {
+		var p testdata.Point
+		if err := yaml.Unmarshal([]byte(input), &p); err == nil {
+			t.Errorf("%s decoded as %+v, want an error", input, p)
+		}
+	}
+}
+
+func TestUnmarshalTupleErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		value any
+		code  codec.Code
+		path  string
+	}{
+		{"extra item", "[1, 2, 3]", &testdata.Point{}, codec.CodeUnknownField, "/2"},
+		{"missing item", "[1]", &testdata.Point{}, codec.CodeMissingField, "/1"},
+		{"item type", `[1, "x"]`, &testdata.Point{}, codec.CodeTypeMismatch, "/1"},
+		{"nested item type", `[[1, 2], [3, "x"]]`, &testdata.Segment{}, codec.CodeTypeMismatch, "/1/1"},
+		{"nested extra item", "[[1, 2], [3, 4, 5]]", &testdata.Segment{}, codec.CodeUnknownField, "/1/2"},
+		{"rest item type", `["a", 1, 2, "x"]`, &testdata.Sample{}, codec.CodeTypeMismatch, "/3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(test.input), test.value)
+			var issue *codec.Issue
+			if !errors.As(err, &issue) {
+				t.Fatalf("got error %v, want an issue", err)
+			}
+			if issue.Code != test.code || issue.Path.JSONPointer() != test.path {
+				t.Errorf("got %v at %q, want %v at %q", issue.Code, issue.Path.JSONPointer(), test.code, test.path)
+			}
+		})
+	}
+}
+
+func TestTupleValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		value testdata.Sample
+		want  []validation.Code
+	}{
+		{"prefix only", testdata.Sample{Item0: "a"}, nil},
+		{"full", testdata.Sample{Item0: "a", Item1: fields.OptionalValue[int64](1), Item2: fields.OptionalValue[int64](2), Rest: []int64{3}}, nil},
+		{"too many", testdata.Sample{Item0: "a", Item1: fields.OptionalValue[int64](1), Item2: fields.OptionalValue[int64](2), Rest: []int64{3, 4}}, []validation.Code{validation.CodeArrMaxItems}},
+		// Rest items are not written after an absent item, so they don't count
+		{"incomplete prefix", testdata.Sample{Item0: "a", Rest: []int64{3, 4, 5, 6}}, nil},
+		{"negative rest item", testdata.Sample{Item0: "a", Item1: fields.OptionalValue[int64](1), Item2: fields.OptionalValue[int64](2), Rest: []int64{-1}}, []validation.Code{validation.CodeIntMin}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []validation.Code
+			for _, issue := range test.value.Validate(nil) {
+				got = append(got, issue.Code)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestContainsValidation(t *testing.T) {
+	user := func(age testdata.Age) testdata.User {
+		return testdata.User{Id: 1, Name: "Ada", Age: fields.OptionalValue(age)}
+	}
+
+	tests := []struct {
+		name  string
+		value interface {
+			Validate(fields.Path) validation.Issues
+		}
+		want []validation.Code
+	}{
+		{"scores matching", &testdata.Scores{95, 10, 90}, nil},
+		{"scores too few", &testdata.Scores{95, 10}, []validation.Code{validation.CodeArrMinContains}},
+		{"roles const", &testdata.Roles{"user", "admin"}, nil},
+		{"roles missing const", &testdata.Roles{"user"}, []validation.Code{validation.CodeArrMinContains}},
+		{"team object", &testdata.Team{user(20), user(10)}, nil},
+		{"team without age", &testdata.Team{{Id: 1, Name: "Ada"}}, nil},
+		{"team too many", &testdata.Team{user(20), user(21), user(22), user(23)}, []validation.Code{validation.CodeArrMaxContains}},
+		{"team none", &testdata.Team{user(10)}, []validation.Code{validation.CodeArrMinContains}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []validation.Code
+			for _, issue := range test.value.Validate(nil) {
+				got = append(got, issue.Code)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// stubServer accepts every request that reaches it.
+type stubServer struct{}
+
+func (stubServer) CreateUser(context.Context, *testdata.CreateUserRequest) (testdata.CreateUserResponse, error) {
+	return testdata.CreateUser201{}, nil
+}
+
+func (stubServer) ImportUsers(context.Context, *testdata.ImportUsersRequest) (testdata.ImportUsersResponse, error) {
+	return testdata.ImportUsers204{}, nil
+}
+
+func TestHandlerValidatesBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"valid body", "/users", `{"name": "Ada"}`, http.StatusCreated},
+		{"invalid body", "/users", `{"name": "` + strings.Repeat("a", 65) + `"}`, http.StatusBadRequest},
+		{"null nullable body", "/users/import", "null", http.StatusNoContent},
+		{"valid nullable body", "/users/import", `[{"id": 1, "name": "Ada"}]`, http.StatusNoContent},
+		{"invalid nullable body", "/users/import", `[{"id": 1, "name": "Ada"}, {"id": 2, "name": "Bob"}, {"id": 3, "name": "Cy"}]`, http.StatusBadRequest},
+		{"invalid nested item", "/users/import", `[{"id": 1, "name": "Ada", "age": 30}]`, http.StatusBadRequest},
+	}
+
+	h := testdata.NewHandler(stubServer{})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body)))
+			if w.Code != test.status {
+				t.Errorf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+			}
+		})
+	}
+}
+
+// plainUser has the fields of testdata.User without its methods, so that encoding/json falls back
+// to reflection for it. Nested types keep their generated methods.
+type plainUser testdata.User
+
+var benchUser = testdata.User{
+	Id:       42,
+	Name:     "Ada Lovelace",
+	Age:      fields.OptionalValue[testdata.Age](36),
+	Address:  fields.OptionalValue(testdata.UserAddress{Street: "12 St James's Square"}),
+	Metadata: fields.OptionalNullableValue(map[string]string{"team": "analytics", "role": "admin"}),
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	b.Run("generated", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := benchUser.MarshalJSON(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("encoding/json", func(b *testing.B) {
+		u := plainUser(benchUser)
+		for b.Loop() {
+			if _, err := json.Marshal(u); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkUnmarshalJSON(b *testing.B) {
+	data, err := json.Marshal(benchUser)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("generated", func(b *testing.B) {
+		for b.Loop() {
+			var u testdata.User
+			if err := u.UnmarshalJSON(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("encoding/json", func(b *testing.B) {
+		for b.Loop() {
+			var u plainUser
+			if err := json.Unmarshal(data, &u); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
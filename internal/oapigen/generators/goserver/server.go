@@ -0,0 +1,537 @@
+package goserver
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/maketaio/openapi/codegen/model"
+	"github.com/maketaio/openapi/internal/util/set"
+)
+
+// writeOperation writes the request type of op and its response sum type, with one variant per
+// status code and media type. The imports needed by the written code are added to imports.
+func writeOperation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, op *model.Operation, imports set.Set[string]) error {
+	imports.Add("net/http")
+
+	writeRequest(buf, namer, op, imports)
+	return writeResponses(buf, r, namer, op, imports)
+}
+
+// writeServer writes the Server interface and NewHandler, which routes requests to the operations
+// of the registry. The imports needed by the written code are added to imports.
+func writeServer(buf *bytes.Buffer, r *model.Registry, namer *declNamer, imports set.Set[string]) {
+	var ops []*model.Operation
+	r.RangeOperations(func(op *model.Operation) bool {
+		ops = append(ops, op)
+		return true
+	})
+
+	if len(ops) == 0 {
+		return
+	}
+
+	imports.Add("context")
+	imports.Add("net/http")
+
+	buf.WriteString("// Server is implemented by the application to serve the operations of the API.\n")
+	buf.WriteString("type Server interface {\n")
+	for _, op := range ops {
+		name := toIdent(op.ID)
+		fmt.Fprintf(buf, "// %s handles %s %s.\n", name, op.Method, op.Path)
+		if len(op.Doc) > 0 {
+			buf.WriteString("//\n")
+			writeDoc(buf, op.Doc)
+		}
+		if op.Deprecated {
+			buf.WriteString("//\n// Deprecated: the operation is deprecated.\n")
+		}
+		fmt.Fprintf(buf, "%s(ctx context.Context, req *%sRequest) (%sResponse, error)\n", name, name, name)
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("// NewHandler returns an http.Handler that routes requests to the operations of s.\n")
+	buf.WriteString("func NewHandler(s Server) http.Handler {\n")
+	buf.WriteString("mux := http.NewServeMux()\n")
+	for _, op := range ops {
+		writeRoute(buf, r, namer, op, imports)
+	}
+	buf.WriteString("return mux\n")
+	buf.WriteString("}\n\n")
+}
+
+func writeRequest(buf *bytes.Buffer, namer *declNamer, op *model.Operation, imports set.Set[string]) {
+	name := toIdent(op.ID)
+	fmt.Fprintf(buf, "// %sRequest is the request of operation %s.\n", name, op.ID)
+	fmt.Fprintf(buf, "type %sRequest struct {\n", name)
+	buf.WriteString("// HTTP is the incoming request. Its body has already been read if the request has a Body field.\n")
+	buf.WriteString("HTTP *http.Request\n")
+	if mt := jsonRequestBody(op); mt != nil {
+		writeDoc(buf, op.RequestBody.Doc)
+		buf.WriteString("Body ")
+		if !op.RequestBody.Required {
+			imports.Add("github.com/maketaio/openapi/runtime/fields")
+		}
+		writeFieldType(buf, namer, model.Field{Type: mt.Type, Required: op.RequestBody.Required})
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n\n")
+}
+
+func writeResponses(buf *bytes.Buffer, r *model.Registry, namer *declNamer, op *model.Operation, imports set.Set[string]) error {
+	name := toIdent(op.ID)
+	variants := variantNames(op)
+
+	fmt.Fprintf(buf, "// %sResponse is the response of operation %s. It is implemented by %s only.\n", name, op.ID, joinNames(variants))
+	fmt.Fprintf(buf, "type %sResponse interface {\n", name)
+	buf.WriteString("// WriteResponse writes the status code, headers and body of the response to w.\n")
+	buf.WriteString("WriteResponse(w http.ResponseWriter) error\n")
+	fmt.Fprintf(buf, "is%sResponse()\n", name)
+	buf.WriteString("}\n\n")
+
+	for _, resp := range op.Responses {
+		if len(resp.Content) == 0 {
+			if err := writeVariant(buf, r, namer, op, resp, nil, imports); err != nil {
+				return err
+			}
+		}
+		for i := range resp.Content {
+			if err := writeVariant(buf, r, namer, op, resp, &resp.Content[i], imports); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeVariant(buf *bytes.Buffer, r *model.Registry, namer *declNamer, op *model.Operation, resp *model.Response, mt *model.MediaType, imports set.Set[string]) error {
+	name := variantName(op, resp, mt)
+
+	if resp.IsDefault() {
+		fmt.Fprintf(buf, "// %s is the default response of operation %s", name, op.ID)
+	} else {
+		fmt.Fprintf(buf, "// %s is the %s response of operation %s", name, resp.Status, op.ID)
+	}
+	if mt != nil && len(resp.Content) > 1 {
+		fmt.Fprintf(buf, " for %s", mt.Name)
+	}
+	buf.WriteString(".\n")
+	if len(resp.Doc) > 0 {
+		buf.WriteString("//\n")
+		writeDoc(buf, resp.Doc)
+	}
+
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	if !isFixedStatus(resp.Status) {
+		buf.WriteString("// Status is the status code to send")
+		if !resp.IsDefault() {
+			fmt.Fprintf(buf, ", which must be in the %s range", resp.Status)
+		}
+		buf.WriteString(".\n")
+		buf.WriteString("Status int\n")
+	}
+	for _, h := range resp.Headers {
+		writeDoc(buf, h.Doc)
+		if h.Deprecated {
+			buf.WriteString("// Deprecated \n")
+		}
+		buf.WriteString(headerFieldName(h))
+		buf.WriteString(" ")
+		if !h.Required {
+			imports.Add("github.com/maketaio/openapi/runtime/fields")
+		}
+		writeFieldType(buf, namer, model.Field{Type: h.Type, Required: h.Required})
+		buf.WriteString("\n")
+	}
+	if mt != nil {
+		buf.WriteString("Body ")
+		writeBodyType(buf, r, namer, mt, imports)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "func (%s) is%sResponse() {}\n\n", name, toIdent(op.ID))
+
+	fmt.Fprintf(buf, "func (o %s) WriteResponse(w http.ResponseWriter) error {\n", name)
+	status := resp.Status
+	switch {
+	case resp.IsDefault():
+		imports.Add("fmt")
+		buf.WriteString("if o.Status < 100 || o.Status > 599 {\n")
+		buf.WriteString("return fmt.Errorf(\"invalid status code %d\", o.Status)\n")
+		buf.WriteString("}\n")
+		status = "o.Status"
+	case !isFixedStatus(resp.Status):
+		imports.Add("fmt")
+		fmt.Fprintf(buf, "if o.Status/100 != %c {\n", resp.Status[0])
+		fmt.Fprintf(buf, "return fmt.Errorf(\"status code %%d is not in the %s range\", o.Status)\n", resp.Status)
+		buf.WriteString("}\n")
+		status = "o.Status"
+	}
+
+	for _, h := range resp.Headers {
+		field := "o." + headerFieldName(h)
+		value := "v"
+		if h.Required {
+			value = field
+		} else {
+			fmt.Fprintf(buf, "if v, ok := %s.Get(); ok {\n", field)
+		}
+
+		expr, err := headerValue(r, h.Type, value, imports)
+		if err != nil {
+			return fmt.Errorf("header %s of operation %s: %w", h.Name, op.ID, err)
+		}
+		fmt.Fprintf(buf, "w.Header().Set(%q, %s)\n", h.Name, expr)
+
+		if !h.Required {
+			buf.WriteString("}\n")
+		}
+	}
+
+	if mt == nil {
+		fmt.Fprintf(buf, "w.WriteHeader(%s)\n", status)
+		buf.WriteString("return nil\n")
+		buf.WriteString("}\n\n")
+		return nil
+	}
+
+	fmt.Fprintf(buf, "w.Header().Set(\"Content-Type\", %q)\n", mt.Name)
+	fmt.Fprintf(buf, "w.WriteHeader(%s)\n", status)
+	switch bodyKind(r, mt) {
+	case bodyJSON:
+		imports.Add("encoding/json")
+		buf.WriteString("return json.NewEncoder(w).Encode(o.Body)\n")
+	case bodyString:
+		imports.Add("io")
+		buf.WriteString("_, err := io.WriteString(w, string(o.Body))\n")
+		buf.WriteString("return err\n")
+	case bodyBytes:
+		buf.WriteString("_, err := w.Write(o.Body)\n")
+		buf.WriteString("return err\n")
+	case bodyReader:
+		imports.Add("io")
+		buf.WriteString("if o.Body == nil {\n")
+		buf.WriteString("return nil\n")
+		buf.WriteString("}\n")
+		buf.WriteString("_, err := io.Copy(w, o.Body)\n")
+		buf.WriteString("return err\n")
+	}
+	buf.WriteString("}\n\n")
+
+	return nil
+}
+
+func writeRoute(buf *bytes.Buffer, r *model.Registry, namer *declNamer, op *model.Operation, imports set.Set[string]) {
+	name := toIdent(op.ID)
+	fmt.Fprintf(buf, "mux.HandleFunc(%q, func(w http.ResponseWriter, r *http.Request) {\n", routePattern(op))
+	fmt.Fprintf(buf, "req := &%sRequest{HTTP: r}\n", name)
+
+	if mt := jsonRequestBody(op); mt != nil {
+		imports.Add("encoding/json")
+		imports.Add("github.com/maketaio/openapi/runtime/problem")
+		if op.RequestBody.Required {
+			buf.WriteString("if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {\n")
+			buf.WriteString("_ = problem.Decode(err).Write(w)\n")
+			buf.WriteString("return\n")
+			buf.WriteString("}\n")
+		} else {
+			imports.Add("errors")
+			imports.Add("io")
+			buf.WriteString("var body ")
+			writeValueType(buf, namer, mt.Type)
+			buf.WriteString("\n")
+			buf.WriteString("if err := json.NewDecoder(r.Body).Decode(&body); err == nil {\n")
+			buf.WriteString("req.Body.Set(body)\n")
+			buf.WriteString("} else if !errors.Is(err, io.EOF) {\n")
+			buf.WriteString("_ = problem.Decode(err).Write(w)\n")
+			buf.WriteString("return\n")
+			buf.WriteString("}\n")
+		}
+
+		writeBodyValidation(buf, r, namer, model.Field{Type: mt.Type, Required: op.RequestBody.Required}, imports)
+	}
+
+	fmt.Fprintf(buf, "resp, err := s.%s(r.Context(), req)\n", name)
+	buf.WriteString("if err != nil || resp == nil {\n")
+	buf.WriteString("http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)\n")
+	buf.WriteString("return\n")
+	buf.WriteString("}\n")
+	buf.WriteString("// The status code has been sent by the time writing fails, so the error cannot be reported.\n")
+	buf.WriteString("_ = resp.WriteResponse(w)\n")
+	buf.WriteString("})\n")
+}
+
+type bodyEncoding int
+
+const (
+	bodyJSON bodyEncoding = iota
+	bodyString
+	bodyBytes
+	bodyReader
+)
+
+// bodyKind decides how a body is encoded. JSON media types are encoded with encoding/json, string
+// schemas of other media types are written as is and everything else is streamed from an io.Reader.
+func bodyKind(r *model.Registry, mt *model.MediaType) bodyEncoding {
+	if isJSONMediaType(mt.Name) {
+		return bodyJSON
+	}
+
+	typ := resolve(r, mt.Type)
+	if typ == nil || typ.Kind != model.TypeString {
+		return bodyReader
+	}
+
+	switch goStringType(typ) {
+	case "string":
+		return bodyString
+	case "[]byte":
+		return bodyBytes
+	}
+	return bodyReader
+}
+
+func writeBodyType(buf *bytes.Buffer, r *model.Registry, namer *declNamer, mt *model.MediaType, imports set.Set[string]) {
+	switch bodyKind(r, mt) {
+	case bodyJSON:
+		if mt.Type == nil {
+			buf.WriteString("any")
+			return
+		}
+		writeValueType(buf, namer, mt.Type)
+	case bodyString, bodyBytes:
+		writeType(buf, namer, mt.Type)
+	case bodyReader:
+		imports.Add("io")
+		buf.WriteString("io.Reader")
+	}
+}
+
+// headerValue returns an expression formatting value, of type typ, as a header value.
+func headerValue(r *model.Registry, typ *model.Type, value string, imports set.Set[string]) (string, error) {
+	res := resolve(r, typ)
+	switch res.Kind {
+	case model.TypeString:
+		switch goStringType(res) {
+		case "string":
+			return "string(" + value + ")", nil
+		case "time.Time":
+			imports.Add("time")
+			if res.Format == "date" {
+				return "time.Time(" + value + ").Format(time.DateOnly)", nil
+			}
+			return "time.Time(" + value + ").Format(time.RFC3339)", nil
+		}
+	case model.TypeInt32, model.TypeInt64:
+		imports.Add("strconv")
+		return "strconv.FormatInt(int64(" + value + "), 10)", nil
+	case model.TypeFloat64:
+		imports.Add("strconv")
+		return "strconv.FormatFloat(float64(" + value + "), 'g', -1, 64)", nil
+	case model.TypeBool:
+		imports.Add("strconv")
+		return "strconv.FormatBool(bool(" + value + "))", nil
+	}
+
+	return "", fmt.Errorf("only string, integer, number and boolean headers are supported at the moment")
+}
+
+// resolve follows references until it finds a type that is not a reference.
+func resolve(r *model.Registry, typ *model.Type) *model.Type {
+	for typ != nil && typ.Kind == model.TypeRef {
+		decl, ok := r.Get(typ.Ref)
+		if !ok {
+			return nil
+		}
+		typ = decl.Type
+	}
+	return typ
+}
+
+// writeBodyValidation validates the decoded request body, rejecting the request with a problem
+// listing the issues. Bodies of declared types call their Validate method, inline ones are checked
+// in place.
+func writeBodyValidation(buf *bytes.Buffer, r *model.Registry, namer *declNamer, field model.Field, imports set.Set[string]) {
+	unwrapped := field.Required && !field.Type.Nullable
+
+	if field.Type.Kind == model.TypeRef {
+		if !requiresValidation(r, field.Type) {
+			return
+		}
+
+		sel := "req.Body"
+		if !unwrapped {
+			writeGetIf(buf, sel, field)
+			sel = "v"
+		}
+		fmt.Fprintf(buf, "if issues := %s.Validate(nil); len(issues) > 0 {\n", sel)
+		buf.WriteString("_ = problem.Validation(issues).Write(w)\n")
+		buf.WriteString("return\n")
+		buf.WriteString("}\n")
+		if !unwrapped {
+			buf.WriteString("}\n")
+		}
+		return
+	}
+
+	var body bytes.Buffer
+	if unwrapped {
+		writeValidationBody(&body, r, namer, "req.Body", field.Type)
+	} else {
+		writeValidationBody(&body, r, namer, "v", field.Type)
+	}
+	if body.Len() == 0 {
+		return
+	}
+
+	imports.Merge(doAnalyzeImports(r, field.Type))
+	buf.WriteString("{\n")
+	buf.WriteString("var issues validation.Issues\n")
+	buf.WriteString("var path fields.Path\n")
+	if unwrapped {
+		buf.Write(body.Bytes())
+	} else {
+		writeGetIf(buf, "req.Body", field)
+		buf.Write(body.Bytes())
+		buf.WriteString("}\n")
+	}
+	buf.WriteString("if len(issues) > 0 {\n")
+	buf.WriteString("_ = problem.Validation(issues).Write(w)\n")
+	buf.WriteString("return\n")
+	buf.WriteString("}\n")
+	buf.WriteString("}\n")
+}
+
+// jsonRequestBody returns the request body of op if it is decoded by the generated handler,
+// which is the case when its only media type is JSON and has a schema.
+func jsonRequestBody(op *model.Operation) *model.MediaType {
+	if op.RequestBody == nil || len(op.RequestBody.Content) != 1 {
+		return nil
+	}
+
+	mt := &op.RequestBody.Content[0]
+	if !isJSONMediaType(mt.Name) || mt.Type == nil {
+		return nil
+	}
+	return mt
+}
+
+func isJSONMediaType(name string) bool {
+	name, _, _ = strings.Cut(name, ";")
+	name = strings.TrimSpace(strings.ToLower(name))
+	return name == "application/json" || strings.HasSuffix(name, "+json")
+}
+
+// variantNames returns the names of all response variants of op, in the order they are written.
+func variantNames(op *model.Operation) []string {
+	var variants []string
+	for _, resp := range op.Responses {
+		if len(resp.Content) == 0 {
+			variants = append(variants, variantName(op, resp, nil))
+		}
+		for i := range resp.Content {
+			variants = append(variants, variantName(op, resp, &resp.Content[i]))
+		}
+	}
+	return variants
+}
+
+// serverNames returns the names of the types and functions written for the operations of the
+// registry, which declarations must not use.
+func serverNames(r *model.Registry) []string {
+	var names []string
+	r.RangeOperations(func(op *model.Operation) bool {
+		name := toIdent(op.ID)
+		names = append(names, name+"Request", name+"Response")
+		names = append(names, variantNames(op)...)
+		return true
+	})
+
+	if len(names) > 0 {
+		names = append(names, "Server", "NewHandler")
+	}
+	return names
+}
+
+// variantName returns the name of the response variant of op for resp and mt, e.g. CreateUser201.
+// The media type is only part of the name when the response has several.
+func variantName(op *model.Operation, resp *model.Response, mt *model.MediaType) string {
+	name := toIdent(op.ID) + toTitle(resp.Status)
+	if mt == nil || len(resp.Content) < 2 {
+		return name
+	}
+
+	// Media types with the same short name are told apart by their position, e.g. JSON and JSON2.
+	taken := map[string]int{}
+	for i := range resp.Content {
+		short := mediaTypeName(resp.Content[i].Name)
+		taken[short]++
+		if &resp.Content[i] != mt {
+			continue
+		}
+
+		if n := taken[short]; n > 1 {
+			short += strconv.Itoa(n)
+		}
+		return name + short
+	}
+
+	return name + mediaTypeName(mt.Name)
+}
+
+// mediaTypeName returns a short name for a media type, e.g. JSON for application/json and
+// ProblemJSON for application/problem+json.
+func mediaTypeName(name string) string {
+	name, _, _ = strings.Cut(name, ";")
+	name = strings.TrimSpace(strings.ToLower(name))
+	switch name {
+	case "application/json":
+		return "JSON"
+	case "application/xml":
+		return "XML"
+	case "text/plain":
+		return "Text"
+	}
+
+	_, sub, _ := strings.Cut(name, "/")
+	if i := strings.LastIndexByte(sub, '+'); i >= 0 {
+		switch sub[i+1:] {
+		case "json":
+			return toIdent(sub[:i]) + "JSON"
+		case "xml":
+			return toIdent(sub[:i]) + "XML"
+		}
+	}
+	return toIdent(sub)
+}
+
+func headerFieldName(h model.Header) string {
+	return toIdent(h.Name) + "Header"
+}
+
+func isFixedStatus(status string) bool {
+	return status != "default" && !strings.HasSuffix(status, "XX")
+}
+
+// routePattern returns the http.ServeMux pattern for op. Path templates already use the same
+// syntax; a trailing slash is anchored so the route does not match every path below it.
+func routePattern(op *model.Operation) string {
+	path := op.Path
+	if strings.HasSuffix(path, "/") {
+		path += "{$}"
+	}
+	return op.Method + " " + path
+}
+
+func joinNames(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
@@ -0,0 +1,255 @@
+package goserver
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/maketaio/openapi/codegen/model"
+)
+
+// Split strategies, see Config.Split.
+const (
+	splitNone        = ""
+	splitDeclaration = "declaration"
+	splitTag         = "tag"
+	splitRoot        = "root"
+)
+
+// generatedHeader is the first line of every generated file. Along with genSuffix, it tells which
+// files in the output directory may be removed as stale.
+const generatedHeader = "// Code generated by oapigen; DO NOT EDIT."
+
+// genSuffix ends the names of the files written when splitting the output.
+const genSuffix = ".gen.go"
+
+// genFile is a file of the output: the declarations and operations it holds, and whether it holds
+// the Server interface and NewHandler.
+type genFile struct {
+	name   string
+	decls  []*model.Declaration
+	ops    []*model.Operation
+	server bool
+}
+
+// planFiles distributes declarations and operations over files according to cfg.Split. Files come
+// in the order their first declaration or operation appears, followed by the server file.
+func planFiles(r *model.Registry, cfg *Config, namer *declNamer) []*genFile {
+	var ops []*model.Operation
+	r.RangeOperations(func(op *model.Operation) bool {
+		ops = append(ops, op)
+		return true
+	})
+
+	if cfg.Split == splitNone {
+		f := &genFile{name: filepath.Base(cfg.Out), ops: ops, server: true}
+		r.Range(func(id string, decl *model.Declaration) bool {
+			f.decls = append(f.decls, decl)
+			return true
+		})
+		return []*genFile{f}
+	}
+
+	var declGroup func(decl *model.Declaration) string
+	var opGroup func(op *model.Operation) string
+
+	switch cfg.Split {
+	case splitDeclaration:
+		declGroup = func(decl *model.Declaration) string { return namer.nameFor(decl.ID) }
+	case splitRoot:
+		declGroup = func(decl *model.Declaration) string {
+			if decl.Loc.Op != nil {
+				return toIdent(decl.Loc.Op.OperationID)
+			}
+			return toTitle(decl.Loc.Root)
+		}
+		opGroup = func(op *model.Operation) string { return toIdent(op.ID) }
+	case splitTag:
+		tags := declTags(r, ops)
+		declGroup = func(decl *model.Declaration) string {
+			if t := tags[decl.ID]; len(t) == 1 {
+				return t[0]
+			}
+			return "Models"
+		}
+		opGroup = opTag
+	}
+
+	names := newFileNamer()
+	serverName := names.reserve("Server")
+
+	groups := map[string]*genFile{}
+	var files []*genFile
+	fileFor := func(group string) *genFile {
+		f, ok := groups[group]
+		if !ok {
+			f = &genFile{name: names.reserve(group)}
+			groups[group] = f
+			files = append(files, f)
+		}
+		return f
+	}
+
+	r.Range(func(id string, decl *model.Declaration) bool {
+		f := fileFor(declGroup(decl))
+		f.decls = append(f.decls, decl)
+		return true
+	})
+
+	if len(ops) == 0 {
+		return files
+	}
+
+	server := &genFile{name: serverName, server: true}
+	for _, op := range ops {
+		if opGroup == nil {
+			server.ops = append(server.ops, op)
+			continue
+		}
+
+		f := fileFor(opGroup(op))
+		f.ops = append(f.ops, op)
+	}
+
+	return append(files, server)
+}
+
+// opTag returns the first tag of op as an identifier, which decides the file of op when splitting
+// by tag.
+func opTag(op *model.Operation) string {
+	for _, tag := range op.Tags {
+		if name := toIdent(tag); name != "" {
+			return name
+		}
+	}
+	return "Untagged"
+}
+
+// declTags returns, for each declaration used by ops, the tags of the operations that use it.
+func declTags(r *model.Registry, ops []*model.Operation) map[string][]string {
+	tags := map[string][]string{}
+
+	for _, op := range ops {
+		tag := opTag(op)
+
+		var reach func(id string)
+		reach = func(id string) {
+			if slices.Contains(tags[id], tag) {
+				return
+			}
+
+			tags[id] = append(tags[id], tag)
+			for _, dep := range r.Dependencies(id) {
+				reach(dep)
+			}
+		}
+
+		for _, id := range r.OperationDependencies(op.ID) {
+			reach(id)
+		}
+	}
+
+	return tags
+}
+
+// fileNamer turns group names into unique file names. All names end with genSuffix so that names
+// such as user_windows.gen.go are not mistaken for build constraints or tests.
+type fileNamer struct {
+	taken map[string]bool
+}
+
+func newFileNamer() *fileNamer {
+	return &fileNamer{taken: map[string]bool{}}
+}
+
+func (n *fileNamer) reserve(group string) string {
+	base := toSnake(group)
+	name := base + genSuffix
+	for i := 2; n.taken[name]; i++ {
+		name = base + "_" + strconv.Itoa(i) + genSuffix
+	}
+
+	n.taken[name] = true
+	return name
+}
+
+// toSnake turns an identifier into snake case, e.g. "CreateUser201Response" becomes
+// "create_user201_response" and "HTTPHeader" becomes "http_header".
+func toSnake(s string) string {
+	runes := []rune(s)
+
+	var b strings.Builder
+	for i, c := range runes {
+		if unicode.IsUpper(c) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// outDir returns the directory the output is written to.
+func outDir(cfg *Config) string {
+	if cfg.Split == splitNone {
+		return filepath.Dir(cfg.Out)
+	}
+	return cfg.Out
+}
+
+// removeStale removes the files in dir that were generated by an earlier run but are not part of
+// files. Only files named like fileNamer names them and starting with the generated header are
+// removed, so that files generated by other tools are left alone.
+func removeStale(dir string, files map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), genSuffix) {
+			continue
+		}
+		if _, ok := files[e.Name()]; ok {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		generated, err := isGenerated(path)
+		if err != nil {
+			return err
+		}
+		if !generated {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isGenerated reports whether the file at path starts with the generated header.
+func isGenerated(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	head := make([]byte, len(generatedHeader)+1)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	return string(head[:n]) == generatedHeader+"\n", nil
+}
@@ -0,0 +1,208 @@
+package goserver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maketaio/openapi/codegen/model"
+	"github.com/pb33f/libopenapi"
+)
+
+const splitSpec = `openapi: 3.1.0
+info: {title: t, version: "1"}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      tags: [users]
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/User'}
+  /orders:
+    post:
+      operationId: createOrder
+      tags: [orders]
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Order'}
+      responses:
+        "204":
+          description: Created
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name: {type: string}
+    Order:
+      type: object
+      properties:
+        user: {$ref: '#/components/schemas/User'}
+        status:
+          type: string
+          enum: [open, closed]
+    Unused:
+      type: string
+`
+
+// describeFiles lists each planned file with its declarations, operations and server part.
+func describeFiles(files []*genFile, namer *declNamer) []string {
+	var got []string
+	for _, f := range files {
+		var parts []string
+		for _, decl := range f.decls {
+			parts = append(parts, namer.nameFor(decl.ID))
+		}
+		for _, op := range f.ops {
+			parts = append(parts, "op "+op.ID)
+		}
+		if f.server {
+			parts = append(parts, "server")
+		}
+		got = append(got, fmt.Sprintf("%s: %s", f.name, strings.Join(parts, ", ")))
+	}
+	return got
+}
+
+func TestPlanFiles(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(splitSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, err := doc.BuildV3Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := model.NewRegistry()
+	if err := r.Collect(dm); err != nil {
+		t.Fatal(err)
+	}
+
+	namer := newDeclNamer()
+	if err := namer.generate(r); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		split string
+		want  []string
+	}{
+		{
+			split: splitNone,
+			want: []string{
+				"api.go: User, OrderStatus, Order, Unused, ListUsers200Response, op listUsers, op createOrder, server",
+			},
+		},
+		{
+			split: splitDeclaration,
+			want: []string{
+				"user.gen.go: User",
+				"order_status.gen.go: OrderStatus",
+				"order.gen.go: Order",
+				"unused.gen.go: Unused",
+				"list_users200_response.gen.go: ListUsers200Response",
+				"server.gen.go: op listUsers, op createOrder, server",
+			},
+		},
+		{
+			split: splitRoot,
+			want: []string{
+				"user.gen.go: User",
+				"order.gen.go: OrderStatus, Order",
+				"unused.gen.go: Unused",
+				"list_users.gen.go: ListUsers200Response, op listUsers",
+				"create_order.gen.go: op createOrder",
+				"server.gen.go: server",
+			},
+		},
+		{
+			// User is used by both tags and Unused by none
+			split: splitTag,
+			want: []string{
+				"models.gen.go: User, Unused",
+				"orders.gen.go: OrderStatus, Order, op createOrder",
+				"users.gen.go: ListUsers200Response, op listUsers",
+				"server.gen.go: server",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("split "+test.split, func(t *testing.T) {
+			cfg := &Config{Out: "out/api.go", Split: test.split}
+			got := describeFiles(planFiles(r, cfg, namer), namer)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestFileNamer(t *testing.T) {
+	names := newFileNamer()
+
+	var got []string
+	for _, group := range []string{"Server", "Server", "HTTPHeader", "User", "user", "UserWindows"} {
+		got = append(got, names.reserve(group))
+	}
+
+	want := []string{"server.gen.go", "server_2.gen.go", "http_header.gen.go", "user.gen.go", "user_2.gen.go", "user_windows.gen.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRemoveStale(t *testing.T) {
+	dir := t.TempDir()
+
+	generated := generatedHeader + "\npackage api\n"
+	existing := map[string]string{
+		"user.gen.go":   generated, // Still planned
+		"order.gen.go":  generated, // Stale
+		"other.go":      generated, // Generated by another tool with the same header
+		"manual.gen.go": "package api\n",
+		"empty.gen.go":  "",
+		"notes.txt":     generated,
+		"user_test.go":  "package api\n",
+		"server.gen.go": generated,       // Stale
+		"short.gen.go":  generatedHeader, // Header without a newline is not the header line
+	}
+	for name, content := range existing {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.gen.go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := removeStale(dir, map[string][]byte{"user.gen.go": nil}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+
+	want := []string{"empty.gen.go", "manual.gen.go", "notes.txt", "other.go", "short.gen.go", "sub.gen.go", "user.gen.go", "user_test.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
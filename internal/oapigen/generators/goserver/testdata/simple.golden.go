@@ -2,18 +2,29 @@
 package testdata
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/maketaio/openapi/runtime/codec"
 	"github.com/maketaio/openapi/runtime/fields"
+	"github.com/maketaio/openapi/runtime/problem"
 	"github.com/maketaio/openapi/runtime/validation"
+	"go.yaml.in/yaml/v4"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
 )
 
 // Age is the generated type for schema Age
 type Age int64
 
-func (o *Age) Validate(path fields.Path) []*validation.Issue {
+func (o *Age) Validate(path fields.Path) validation.Issues {
 	if o == nil {
 		return nil
 	}
-	var issues []*validation.Issue
+	var issues validation.Issues
 	if *o >= 24 {
 		issues = append(issues, validation.NewIntExclMaxIssue(path, 24))
 	}
@@ -23,10 +34,1193 @@ func (o *Age) Validate(path fields.Path) []*validation.Issue {
 	return issues
 }
 
+// UserAddress is the generated type for schema User/properties/address
+type UserAddress struct {
+	Street string `json:"street" yaml:"street"`
+}
+
+func (o UserAddress) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	e.Key("street")
+	e.String(o.Street)
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *UserAddress) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = UserAddress{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "street":
+			if d.Null() {
+				return nil
+			}
+			return codec.String(d, &o.Street)
+		}
+		return d.Skip()
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+func (o *UserAddress) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		path := path.Field("street")
+		if len(o.Street) < 1 {
+			issues = append(issues, validation.NewStrMinLenIssue(path, 1))
+		}
+	}
+	return issues
+}
+
 // User is the generated type for schema User
 type User struct {
-	Id       int64                                      `json:"id"`
-	Name     string                                     `json:"name"`
-	Age      fields.Optional[Age]                       `json:"age,omitzero"`
-	Metadata fields.OptionalNullable[map[string]string] `json:"metadata,omitzero"`
+	Id       int64                                      `json:"id" yaml:"id"`
+	Name     string                                     `json:"name" yaml:"name"`
+	Age      fields.Optional[Age]                       `json:"age,omitzero" yaml:"age,omitempty"`
+	Address  fields.Optional[UserAddress]               `json:"address,omitzero" yaml:"address,omitempty"`
+	Metadata fields.OptionalNullable[map[string]string] `json:"metadata,omitzero" yaml:"metadata,omitempty"`
+}
+
+func (o User) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	e.Key("id")
+	e.Int(o.Id)
+	e.Key("name")
+	e.String(o.Name)
+	if v, ok := o.Age.Get(); ok {
+		e.Key("age")
+		e.Int(int64(v))
+	}
+	if v, ok := o.Address.Get(); ok {
+		e.Key("address")
+		e.Marshal(v)
+	}
+	if o.Metadata.IsPresent() {
+		e.Key("metadata")
+		if v, ok := o.Metadata.Get(); ok {
+			e.Value(v)
+		} else {
+			e.Null()
+		}
+	}
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *User) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = User{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "id":
+			if d.Null() {
+				return nil
+			}
+			return codec.Int(d, &o.Id)
+		case "name":
+			if d.Null() {
+				return nil
+			}
+			return codec.String(d, &o.Name)
+		case "age":
+			if d.Null() {
+				return nil
+			}
+			var v Age
+			if err := codec.Int(d, &v); err != nil {
+				return err
+			}
+			o.Age.Set(v)
+			return nil
+		case "address":
+			if d.Null() {
+				return nil
+			}
+			var v UserAddress
+			if err := codec.Unmarshal(d, &v); err != nil {
+				return err
+			}
+			o.Address.Set(v)
+			return nil
+		case "metadata":
+			if d.Null() {
+				o.Metadata.SetNull()
+				return nil
+			}
+			var v map[string]string
+			if err := codec.Any(d, &v); err != nil {
+				return err
+			}
+			o.Metadata.Set(v)
+			return nil
+		}
+		return d.Skip()
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+func (o *User) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		path := path.Field("age")
+		if v, ok := o.Age.Get(); ok {
+			issues = append(issues, v.Validate(path)...)
+		}
+	}
+	{
+		path := path.Field("address")
+		if v, ok := o.Address.Get(); ok {
+			issues = append(issues, v.Validate(path)...)
+		}
+	}
+	return issues
+}
+
+func (o *User) UnmarshalYAML(node *yaml.Node) error {
+	type alias User
+	if err := node.Decode((*alias)(o)); err != nil {
+		return err
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !fields.IsYAMLNull(node.Content[i+1]) {
+			continue
+		}
+		switch node.Content[i].Value {
+		case "metadata":
+			o.Metadata.SetNull()
+		}
+	}
+	return nil
+}
+
+// Tags is the generated type for schema Tags
+type Tags []string
+
+func (o *Tags) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	for _, d := range validation.FindDuplicates(*o) {
+		issues = append(issues, validation.NewArrUniqueItemsIssue(path, d.First, d.Dup))
+	}
+	return issues
+}
+
+// Point is the generated type for schema Point
+type Point struct {
+	Item0 float64
+	Item1 float64
+}
+
+func (o *Point) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = Point{}
+	n := 0
+	err := d.Array(func(i int) error {
+		n++
+		switch i {
+		case 0:
+			if d.Null() {
+				return nil
+			}
+			return codec.Float(d, &o.Item0)
+		case 1:
+			if d.Null() {
+				return nil
+			}
+			return codec.Float(d, &o.Item1)
+		}
+		return &codec.Issue{
+			Path:    d.Path(),
+			Code:    codec.CodeUnknownField,
+			Message: "tuple must have at most 2 items",
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if n < 2 {
+		return &codec.Issue{
+			Path:    fields.Path{}.Index(n),
+			Code:    codec.CodeMissingField,
+			Message: "tuple item " + strconv.Itoa(n) + " is required",
+		}
+	}
+	return d.End()
+}
+
+func (o Point) MarshalJSON() ([]byte, error) {
+	items := make([]any, 0, 2)
+	items = append(items, o.Item0)
+	items = append(items, o.Item1)
+	return json.Marshal(items)
+}
+
+func (o *Point) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		path := path.Index(0)
+		if o.Item0 > 180 {
+			issues = append(issues, validation.NewNumMaxIssue(path, 180))
+		}
+		if o.Item0 < -180 {
+			issues = append(issues, validation.NewNumMinIssue(path, -180))
+		}
+	}
+	{
+		path := path.Index(1)
+		if o.Item1 > 90 {
+			issues = append(issues, validation.NewNumMaxIssue(path, 90))
+		}
+		if o.Item1 < -90 {
+			issues = append(issues, validation.NewNumMinIssue(path, -90))
+		}
+	}
+	return issues
+}
+
+func (o Point) MarshalYAML() (any, error) {
+	return codec.MarshalYAML(o)
+}
+
+func (o *Point) UnmarshalYAML(node *yaml.Node) error {
+	return codec.UnmarshalYAML(node, o)
+}
+
+// Segment is the generated type for schema Segment
+type Segment struct {
+	Item0 Point
+	Item1 Point
+}
+
+func (o *Segment) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = Segment{}
+	n := 0
+	err := d.Array(func(i int) error {
+		n++
+		switch i {
+		case 0:
+			if d.Null() {
+				return nil
+			}
+			return codec.Unmarshal(d, &o.Item0)
+		case 1:
+			if d.Null() {
+				return nil
+			}
+			return codec.Unmarshal(d, &o.Item1)
+		}
+		return &codec.Issue{
+			Path:    d.Path(),
+			Code:    codec.CodeUnknownField,
+			Message: "tuple must have at most 2 items",
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if n < 2 {
+		return &codec.Issue{
+			Path:    fields.Path{}.Index(n),
+			Code:    codec.CodeMissingField,
+			Message: "tuple item " + strconv.Itoa(n) + " is required",
+		}
+	}
+	return d.End()
+}
+
+func (o Segment) MarshalJSON() ([]byte, error) {
+	items := make([]any, 0, 2)
+	items = append(items, o.Item0)
+	items = append(items, o.Item1)
+	return json.Marshal(items)
+}
+
+func (o *Segment) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		path := path.Index(0)
+		issues = append(issues, o.Item0.Validate(path)...)
+	}
+	{
+		path := path.Index(1)
+		issues = append(issues, o.Item1.Validate(path)...)
+	}
+	return issues
+}
+
+func (o Segment) MarshalYAML() (any, error) {
+	return codec.MarshalYAML(o)
+}
+
+func (o *Segment) UnmarshalYAML(node *yaml.Node) error {
+	return codec.UnmarshalYAML(node, o)
+}
+
+// Sample is the generated type for schema Sample
+type Sample struct {
+	Item0 string
+	Item1 fields.Optional[int64]
+	Item2 fields.Optional[int64]
+	Rest  []int64
+}
+
+func (o *Sample) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = Sample{}
+	n := 0
+	err := d.Array(func(i int) error {
+		n++
+		switch i {
+		case 0:
+			if d.Null() {
+				return nil
+			}
+			return codec.String(d, &o.Item0)
+		case 1:
+			if d.Null() {
+				return nil
+			}
+			var v int64
+			if err := codec.Int(d, &v); err != nil {
+				return err
+			}
+			o.Item1.Set(v)
+			return nil
+		case 2:
+			if d.Null() {
+				return nil
+			}
+			var v int64
+			if err := codec.Int(d, &v); err != nil {
+				return err
+			}
+			o.Item2.Set(v)
+			return nil
+		}
+		var v int64
+		if err := codec.Int(d, &v); err != nil {
+			return err
+		}
+		o.Rest = append(o.Rest, v)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return &codec.Issue{
+			Path:    fields.Path{}.Index(n),
+			Code:    codec.CodeMissingField,
+			Message: "tuple item " + strconv.Itoa(n) + " is required",
+		}
+	}
+	return d.End()
+}
+
+func (o Sample) MarshalJSON() ([]byte, error) {
+	items := make([]any, 0, 3+len(o.Rest))
+	items = append(items, o.Item0)
+	if !o.Item1.IsPresent() {
+		return json.Marshal(items)
+	}
+	items = append(items, o.Item1)
+	if !o.Item2.IsPresent() {
+		return json.Marshal(items)
+	}
+	items = append(items, o.Item2)
+	for _, v := range o.Rest {
+		items = append(items, v)
+	}
+	return json.Marshal(items)
+}
+
+func (o *Sample) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	n := 1
+	if o.Item1.IsPresent() {
+		n++
+		if o.Item2.IsPresent() {
+			n++
+			n += len(o.Rest)
+		}
+	}
+	if n > 4 {
+		issues = append(issues, validation.NewArrMaxItemsIssue(path, 4))
+	}
+	for i, item := range o.Rest {
+		path := path.Index(3 + i)
+		if item < 0 {
+			issues = append(issues, validation.NewIntMinIssue(path, 0))
+		}
+	}
+	return issues
+}
+
+func (o Sample) MarshalYAML() (any, error) {
+	return codec.MarshalYAML(o)
+}
+
+func (o *Sample) UnmarshalYAML(node *yaml.Node) error {
+	return codec.UnmarshalYAML(node, o)
+}
+
+// Labels is the generated type for schema Labels
+type Labels map[string]string
+
+func (o *Labels) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	for k := range *o {
+		path := path.Key(k)
+		if len(k) > 63 {
+			issues = append(issues, validation.NewStrMaxLenIssue(path, 63))
+		}
+		if !validation.MatchString("^[a-z][a-z0-9_]*$", k) {
+			issues = append(issues, validation.NewStrPatternIssue(path, "^[a-z][a-z0-9_]*$"))
+		}
+	}
+	return issues
+}
+
+// Invoice is the generated type for schema Invoice
+type Invoice struct {
+	Country   string                  `json:"country" yaml:"country"`
+	VatNumber fields.Optional[string] `json:"vatNumber,omitzero" yaml:"vatNumber,omitempty"`
+}
+
+func (o Invoice) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	e.Key("country")
+	e.String(o.Country)
+	if v, ok := o.VatNumber.Get(); ok {
+		e.Key("vatNumber")
+		e.String(v)
+	}
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *Invoice) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = Invoice{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "country":
+			if d.Null() {
+				return nil
+			}
+			return codec.String(d, &o.Country)
+		case "vatNumber":
+			if d.Null() {
+				return nil
+			}
+			var v string
+			if err := codec.String(d, &v); err != nil {
+				return err
+			}
+			o.VatNumber.Set(v)
+			return nil
+		}
+		return d.Skip()
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+func (o *Invoice) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		ifMatched := true
+		if !(o.Country == "DE" || o.Country == "FR") {
+			ifMatched = false
+		}
+		if ifMatched {
+			if !o.VatNumber.IsPresent() {
+				issues = append(issues, validation.NewObjIfThenIssue(path.Field("vatNumber")))
+			}
+		}
+	}
+	return issues
+}
+
+// Scan implements sql.Scanner by decoding a JSON column. NULL resets o to its zero value.
+func (o *Invoice) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Invoice", src)
+	}
+	*o = Invoice{}
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, o)
+}
+
+// Value implements driver.Valuer by encoding o as JSON.
+func (o Invoice) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+// Scores is the generated type for schema Scores
+type Scores []int64
+
+func (o *Scores) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		contains := int64(0)
+		for _, item := range *o {
+			{
+				v := item
+				var issues validation.Issues
+				if v < 90 {
+					issues = append(issues, validation.NewIntMinIssue(path, 90))
+				}
+				if len(issues) == 0 {
+					contains++
+					continue
+				}
+			}
+		}
+		if contains < 2 {
+			issues = append(issues, validation.NewArrMinContainsIssue(path, 2, contains))
+		}
+	}
+	return issues
+}
+
+// Roles is the generated type for schema Roles
+type Roles []string
+
+func (o *Roles) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		contains := int64(0)
+		for _, item := range *o {
+			{
+				v := item
+				if v == "admin" {
+					contains++
+					continue
+				}
+			}
+		}
+		if contains < 1 {
+			issues = append(issues, validation.NewArrMinContainsIssue(path, 1, contains))
+		}
+	}
+	return issues
+}
+
+// TeamContains is the generated type for schema Team/contains
+type TeamContains struct {
+	Age                  fields.Optional[int64]     `json:"age,omitzero" yaml:"age,omitempty"`
+	AdditionalProperties map[string]json.RawMessage `json:"-" yaml:"-"`
+}
+
+func (o TeamContains) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	if v, ok := o.Age.Get(); ok {
+		e.Key("age")
+		e.Int(v)
+	}
+	for _, k := range slices.Sorted(maps.Keys(o.AdditionalProperties)) {
+		switch k {
+		case "age":
+			continue
+		}
+		v := o.AdditionalProperties[k]
+		e.Key(k)
+		e.Value(v)
+	}
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *TeamContains) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = TeamContains{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "age":
+			if d.Null() {
+				return nil
+			}
+			var v int64
+			if err := codec.Int(d, &v); err != nil {
+				return err
+			}
+			o.Age.Set(v)
+			return nil
+		}
+		var v json.RawMessage
+		if err := codec.Any(d, &v); err != nil {
+			return err
+		}
+		if o.AdditionalProperties == nil {
+			o.AdditionalProperties = map[string]json.RawMessage{}
+		}
+		o.AdditionalProperties[key] = v
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+func (o *TeamContains) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		path := path.Field("age")
+		if v, ok := o.Age.Get(); ok {
+			if v < 18 {
+				issues = append(issues, validation.NewIntMinIssue(path, 18))
+			}
+		}
+	}
+	return issues
+}
+
+func (o TeamContains) MarshalYAML() (any, error) {
+	return codec.MarshalYAML(o)
+}
+
+func (o *TeamContains) UnmarshalYAML(node *yaml.Node) error {
+	return codec.UnmarshalYAML(node, o)
+}
+
+// Team is the generated type for schema Team
+type Team []User
+
+func (o *Team) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		contains := int64(0)
+		for _, item := range *o {
+			{
+				var v TeamContains
+				if data, err := json.Marshal(item); err == nil && json.Unmarshal(data, &v) == nil {
+					var issues validation.Issues
+					issues = append(issues, v.Validate(path)...)
+					if len(issues) == 0 {
+						contains++
+						continue
+					}
+				}
+			}
+		}
+		if contains < 1 {
+			issues = append(issues, validation.NewArrMinContainsIssue(path, 1, contains))
+		}
+		if contains > 3 {
+			issues = append(issues, validation.NewArrMaxContainsIssue(path, 3, contains))
+		}
+	}
+	for i, item := range *o {
+		path := path.Index(i)
+		issues = append(issues, item.Validate(path)...)
+	}
+	return issues
+}
+
+// Color is the generated type for schema Color
+type Color []int64
+
+func (o *Color) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	if len(*o) != 3 {
+		issues = append(issues, validation.NewArrLenIssue(path, 3))
+	}
+	for i, item := range *o {
+		path := path.Index(i)
+		if item > 255 {
+			issues = append(issues, validation.NewIntMaxIssue(path, 255))
+		}
+		if item < 0 {
+			issues = append(issues, validation.NewIntMinIssue(path, 0))
+		}
+	}
+	return issues
+}
+
+// Settings is the generated type for schema Settings
+type Settings struct {
+	Theme                fields.Optional[string]    `json:"theme,omitzero" yaml:"theme,omitempty"`
+	AdditionalProperties map[string]json.RawMessage `json:"-" yaml:"-"`
+}
+
+func (o Settings) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	if v, ok := o.Theme.Get(); ok {
+		e.Key("theme")
+		e.String(v)
+	}
+	for _, k := range slices.Sorted(maps.Keys(o.AdditionalProperties)) {
+		switch k {
+		case "theme":
+			continue
+		}
+		v := o.AdditionalProperties[k]
+		e.Key(k)
+		e.Value(v)
+	}
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *Settings) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = Settings{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "theme":
+			if d.Null() {
+				return nil
+			}
+			var v string
+			if err := codec.String(d, &v); err != nil {
+				return err
+			}
+			o.Theme.Set(v)
+			return nil
+		}
+		var v json.RawMessage
+		if err := codec.Any(d, &v); err != nil {
+			return err
+		}
+		if o.AdditionalProperties == nil {
+			o.AdditionalProperties = map[string]json.RawMessage{}
+		}
+		o.AdditionalProperties[key] = v
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+func (o Settings) MarshalYAML() (any, error) {
+	return codec.MarshalYAML(o)
+}
+
+func (o *Settings) UnmarshalYAML(node *yaml.Node) error {
+	return codec.UnmarshalYAML(node, o)
+}
+
+// Quota is the generated type for schema Quota
+type Quota struct {
+	Plan                 string                          `json:"plan" yaml:"plan"`
+	Note                 fields.OptionalNullable[string] `json:"note,omitzero" yaml:"note,omitempty"`
+	AdditionalProperties map[string]int64                `json:"-" yaml:",inline"`
+}
+
+func (o Quota) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	e.Key("plan")
+	e.String(o.Plan)
+	if o.Note.IsPresent() {
+		e.Key("note")
+		if v, ok := o.Note.Get(); ok {
+			e.String(v)
+		} else {
+			e.Null()
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(o.AdditionalProperties)) {
+		switch k {
+		case "plan", "note":
+			continue
+		}
+		v := o.AdditionalProperties[k]
+		e.Key(k)
+		e.Int(v)
+	}
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *Quota) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = Quota{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "plan":
+			if d.Null() {
+				return nil
+			}
+			return codec.String(d, &o.Plan)
+		case "note":
+			if d.Null() {
+				o.Note.SetNull()
+				return nil
+			}
+			var v string
+			if err := codec.String(d, &v); err != nil {
+				return err
+			}
+			o.Note.Set(v)
+			return nil
+		}
+		var v int64
+		if err := codec.Int(d, &v); err != nil {
+			return err
+		}
+		if o.AdditionalProperties == nil {
+			o.AdditionalProperties = map[string]int64{}
+		}
+		o.AdditionalProperties[key] = v
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+func (o *Quota) UnmarshalYAML(node *yaml.Node) error {
+	type alias Quota
+	if err := node.Decode((*alias)(o)); err != nil {
+		return err
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !fields.IsYAMLNull(node.Content[i+1]) {
+			continue
+		}
+		switch node.Content[i].Value {
+		case "note":
+			o.Note.SetNull()
+		}
+	}
+	return nil
+}
+
+// Account is the generated type for schema Account
+type Account = User
+
+// CreateUserRequestBody is the generated type for schema operations/createUser/requestBody/application~1json
+type CreateUserRequestBody struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+func (o CreateUserRequestBody) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	e.Key("name")
+	e.String(o.Name)
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *CreateUserRequestBody) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = CreateUserRequestBody{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "name":
+			if d.Null() {
+				return nil
+			}
+			return codec.String(d, &o.Name)
+		}
+		return d.Skip()
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+func (o *CreateUserRequestBody) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	{
+		path := path.Field("name")
+		if len(o.Name) > 64 {
+			issues = append(issues, validation.NewStrMaxLenIssue(path, 64))
+		}
+	}
+	return issues
+}
+
+// Conflict is the generated type for schema operations/createUser/responses/409/application~1json
+type Conflict struct {
+	Message fields.Optional[string] `json:"message,omitzero" yaml:"message,omitempty"`
+}
+
+func (o Conflict) MarshalJSON() ([]byte, error) {
+	var e codec.Encoder
+	e.BeginObject()
+	if v, ok := o.Message.Get(); ok {
+		e.Key("message")
+		e.String(v)
+	}
+	e.EndObject()
+	return e.Bytes()
+}
+
+func (o *Conflict) UnmarshalJSON(data []byte) error {
+	d := codec.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	*o = Conflict{}
+	err := d.Object(func(key string) error {
+		switch key {
+		case "message":
+			if d.Null() {
+				return nil
+			}
+			var v string
+			if err := codec.String(d, &v); err != nil {
+				return err
+			}
+			o.Message.Set(v)
+			return nil
+		}
+		return d.Skip()
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+// ImportUsersRequestBody is the generated type for schema operations/importUsers/requestBody/application~1json
+type ImportUsersRequestBody []User
+
+func (o *ImportUsersRequestBody) Validate(path fields.Path) validation.Issues {
+	if o == nil {
+		return nil
+	}
+	var issues validation.Issues
+	if len(*o) > 2 {
+		issues = append(issues, validation.NewArrMaxItemsIssue(path, 2))
+	}
+	for i, item := range *o {
+		path := path.Index(i)
+		issues = append(issues, item.Validate(path)...)
+	}
+	return issues
+}
+
+// CreateUserRequest is the request of operation createUser.
+type CreateUserRequest struct {
+	// HTTP is the incoming request. Its body has already been read if the request has a Body field.
+	HTTP *http.Request
+	Body CreateUserRequestBody
+}
+
+// CreateUserResponse is the response of operation createUser. It is implemented by CreateUser201 and CreateUser409 only.
+type CreateUserResponse interface {
+	// WriteResponse writes the status code, headers and body of the response to w.
+	WriteResponse(w http.ResponseWriter) error
+	isCreateUserResponse()
+}
+
+// CreateUser201 is the 201 response of operation createUser.
+//
+// Created
+type CreateUser201 struct {
+	Body User
+}
+
+func (CreateUser201) isCreateUserResponse() {}
+
+func (o CreateUser201) WriteResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+	return json.NewEncoder(w).Encode(o.Body)
+}
+
+// CreateUser409 is the 409 response of operation createUser.
+//
+// Conflict
+type CreateUser409 struct {
+	Body Conflict
+}
+
+func (CreateUser409) isCreateUserResponse() {}
+
+func (o CreateUser409) WriteResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+	return json.NewEncoder(w).Encode(o.Body)
+}
+
+// ImportUsersRequest is the request of operation importUsers.
+type ImportUsersRequest struct {
+	// HTTP is the incoming request. Its body has already been read if the request has a Body field.
+	HTTP *http.Request
+	Body fields.Nullable[ImportUsersRequestBody]
+}
+
+// ImportUsersResponse is the response of operation importUsers. It is implemented by ImportUsers204 only.
+type ImportUsersResponse interface {
+	// WriteResponse writes the status code, headers and body of the response to w.
+	WriteResponse(w http.ResponseWriter) error
+	isImportUsersResponse()
+}
+
+// ImportUsers204 is the 204 response of operation importUsers.
+//
+// Imported
+type ImportUsers204 struct {
+}
+
+func (ImportUsers204) isImportUsersResponse() {}
+
+func (o ImportUsers204) WriteResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+// Server is implemented by the application to serve the operations of the API.
+type Server interface {
+	// CreateUser handles POST /users.
+	CreateUser(ctx context.Context, req *CreateUserRequest) (CreateUserResponse, error)
+	// ImportUsers handles POST /users/import.
+	ImportUsers(ctx context.Context, req *ImportUsersRequest) (ImportUsersResponse, error)
+}
+
+// NewHandler returns an http.Handler that routes requests to the operations of s.
+func NewHandler(s Server) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		req := &CreateUserRequest{HTTP: r}
+		if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+			_ = problem.Decode(err).Write(w)
+			return
+		}
+		if issues := req.Body.Validate(nil); len(issues) > 0 {
+			_ = problem.Validation(issues).Write(w)
+			return
+		}
+		resp, err := s.CreateUser(r.Context(), req)
+		if err != nil || resp == nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		// The status code has been sent by the time writing fails, so the error cannot be reported.
+		_ = resp.WriteResponse(w)
+	})
+	mux.HandleFunc("POST /users/import", func(w http.ResponseWriter, r *http.Request) {
+		req := &ImportUsersRequest{HTTP: r}
+		if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+			_ = problem.Decode(err).Write(w)
+			return
+		}
+		if v, ok := req.Body.Get(); ok {
+			if issues := v.Validate(nil); len(issues) > 0 {
+				_ = problem.Validation(issues).Write(w)
+				return
+			}
+		}
+		resp, err := s.ImportUsers(r.Context(), req)
+		if err != nil || resp == nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		// The status code has been sent by the time writing fails, so the error cannot be reported.
+		_ = resp.WriteResponse(w)
+	})
+	return mux
 }
@@ -0,0 +1,84 @@
+package goserver
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/maketaio/openapi/codegen/model"
+)
+
+// writeYAML writes the YAML methods of the declarations that struct tags cannot describe. Tuples,
+// which are sequences, and objects holding additional properties as raw JSON go through their JSON
+// methods. Objects with nullable fields only need to apply explicit nulls, see writeUnmarshalYAML.
+func writeYAML(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
+	if !viaJSONForYAML(decl.Type) {
+		writeUnmarshalYAML(buf, namer, decl)
+		return
+	}
+
+	declName := namer.nameFor(decl.ID)
+
+	fmt.Fprintf(buf, "func (o %s) MarshalYAML() (any, error) {\n", declName)
+	buf.WriteString("return codec.MarshalYAML(o)\n")
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "func (o *%s) UnmarshalYAML(node *yaml.Node) error {\n", declName)
+	buf.WriteString("return codec.UnmarshalYAML(node, o)\n")
+	buf.WriteString("}\n\n")
+}
+
+// viaJSONForYAML reports whether the YAML methods of typ go through its JSON methods.
+func viaJSONForYAML(typ *model.Type) bool {
+	if typ.Kind == model.TypeTuple {
+		return true
+	}
+	return typ.Kind == model.TypeObject && !isMapShaped(typ) && hasExtraProps(typ) &&
+		extraPropsType(typ).Kind == model.TypeUnknown
+}
+
+// writeUnmarshalYAML writes an UnmarshalYAML method for objects with nullable fields. The yaml
+// package skips unmarshalers for null nodes, so explicit nulls are applied after decoding.
+func writeUnmarshalYAML(buf *bytes.Buffer, namer *declNamer, decl *model.Declaration) {
+	if !hasYAMLNulls(decl.Type) {
+		return
+	}
+
+	declName := namer.nameFor(decl.ID)
+	fmt.Fprintf(buf, "func (o *%s) UnmarshalYAML(node *yaml.Node) error {\n", declName)
+	fmt.Fprintf(buf, "type alias %s\n", declName)
+	buf.WriteString("if err := node.Decode((*alias)(o)); err != nil {\n")
+	buf.WriteString("return err\n")
+	buf.WriteString("}\n")
+	buf.WriteString("if node.Kind != yaml.MappingNode {\n")
+	buf.WriteString("return nil\n")
+	buf.WriteString("}\n")
+	buf.WriteString("for i := 0; i+1 < len(node.Content); i += 2 {\n")
+	buf.WriteString("if !fields.IsYAMLNull(node.Content[i+1]) {\n")
+	buf.WriteString("continue\n")
+	buf.WriteString("}\n")
+	buf.WriteString("switch node.Content[i].Value {\n")
+	for _, field := range decl.Type.Fields {
+		if field.Type.Nullable && isWrapped(field) {
+			fmt.Fprintf(buf, "case %q:\n", field.Name)
+			fmt.Fprintf(buf, "o.%s.SetNull()\n", toTitle(field.Name))
+		}
+	}
+	buf.WriteString("}\n")
+	buf.WriteString("}\n")
+	buf.WriteString("return nil\n")
+	buf.WriteString("}\n\n")
+}
+
+// hasYAMLNulls reports whether typ is a struct with nullable wrapped fields.
+func hasYAMLNulls(typ *model.Type) bool {
+	if typ.Kind != model.TypeObject || isMapShaped(typ) {
+		return false
+	}
+
+	for _, field := range typ.Fields {
+		if field.Type.Nullable && isWrapped(field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/maketaio/openapi/runtime/fields"
+)
+
+// codeNames holds the stable names of codes. Names are part of the wire format and must never
+// change, unlike the numeric values of the codes.
+var codeNames = map[Code]string{
+	CodeTypeMismatch: "type.mismatch",
+	CodeMissingField: "field.missing",
+	CodeUnknownField: "field.unknown",
+}
+
+var kindNames = map[ValueKind]string{
+	KindString:  "string",
+	KindNumber:  "number",
+	KindInteger: "integer",
+	KindBoolean: "boolean",
+	KindObject:  "object",
+	KindArray:   "array",
+	KindNull:    "null",
+	KindAny:     "any",
+}
+
+// codesByName and kindsByName map names back for unmarshaling.
+var (
+	codesByName = byName(codeNames)
+	kindsByName = byName(kindNames)
+)
+
+func byName[T comparable](names map[T]string) map[string]T {
+	res := make(map[string]T, len(names))
+	for v, name := range names {
+		res[name] = v
+	}
+	return res
+}
+
+// String returns the stable name of c, e.g. "field.unknown".
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", int(c))
+}
+
+func (c Code) MarshalText() ([]byte, error) {
+	name, ok := codeNames[c]
+	if !ok {
+		return nil, fmt.Errorf("codec: unknown code %d", int(c))
+	}
+	return []byte(name), nil
+}
+
+func (c *Code) UnmarshalText(text []byte) error {
+	code, ok := codesByName[string(text)]
+	if !ok {
+		return fmt.Errorf("codec: unknown code %q", text)
+	}
+	*c = code
+	return nil
+}
+
+// String returns the JSON Schema type name of k, e.g. "integer".
+func (k ValueKind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("ValueKind(%d)", int(k))
+}
+
+func (k ValueKind) MarshalText() ([]byte, error) {
+	name, ok := kindNames[k]
+	if !ok {
+		return nil, fmt.Errorf("codec: unknown value kind %d", int(k))
+	}
+	return []byte(name), nil
+}
+
+func (k *ValueKind) UnmarshalText(text []byte) error {
+	kind, ok := kindsByName[string(text)]
+	if !ok {
+		return fmt.Errorf("codec: unknown value kind %q", text)
+	}
+	*k = kind
+	return nil
+}
+
+// issueJSON is the wire representation of Issue. Expected and Actual are only set for type
+// mismatches, since their zero value is a valid kind.
+type issueJSON struct {
+	Path     fields.Path `json:"path"`
+	Code     Code        `json:"code"`
+	Expected *ValueKind  `json:"expected,omitempty"`
+	Actual   *ValueKind  `json:"actual,omitempty"`
+	Allowed  []string    `json:"allowed,omitempty"`
+	Message  string      `json:"message"`
+}
+
+func (i Issue) MarshalJSON() ([]byte, error) {
+	v := issueJSON{
+		Path:    i.Path,
+		Code:    i.Code,
+		Allowed: i.Allowed,
+		Message: i.Message,
+	}
+	if i.Code == CodeTypeMismatch {
+		v.Expected = &i.Expected
+		v.Actual = &i.Actual
+	}
+	return json.Marshal(v)
+}
+
+func (i *Issue) UnmarshalJSON(data []byte) error {
+	var v issueJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*i = Issue{
+		Path:    v.Path,
+		Code:    v.Code,
+		Allowed: v.Allowed,
+		Message: v.Message,
+	}
+	if v.Expected != nil {
+		i.Expected = *v.Expected
+	}
+	if v.Actual != nil {
+		i.Actual = *v.Actual
+	}
+	return nil
+}
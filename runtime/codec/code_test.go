@@ -0,0 +1,113 @@
+package codec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/maketaio/openapi/runtime/fields"
+)
+
+func TestCodeText(t *testing.T) {
+	for c := CodeTypeMismatch; c <= CodeUnknownField; c++ {
+		text, err := c.MarshalText()
+		if err != nil {
+			t.Fatalf("code %d: %v", int(c), err)
+		}
+		if string(text) != c.String() {
+			t.Errorf("code %d: got text %q and string %q", int(c), text, c.String())
+		}
+
+		var got Code
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if got != c {
+			t.Errorf("%s decoded as %d, want %d", text, int(got), int(c))
+		}
+	}
+
+	if _, err := Code(-1).MarshalText(); err == nil {
+		t.Error("unknown code was encoded")
+	}
+	if got := Code(42).String(); got != "Code(42)" {
+		t.Errorf("got %q for an unknown code", got)
+	}
+	for _, name := range []string{"", "type", "Type.Mismatch", "int.max"} {
+		var c Code
+		if err := c.UnmarshalText([]byte(name)); err == nil {
+			t.Errorf("unknown name %q decoded as %d", name, int(c))
+		}
+	}
+}
+
+func TestValueKindText(t *testing.T) {
+	for k := KindString; k <= KindAny; k++ {
+		text, err := k.MarshalText()
+		if err != nil {
+			t.Fatalf("kind %d: %v", int(k), err)
+		}
+
+		var got ValueKind
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if got != k {
+			t.Errorf("%s decoded as %d, want %d", text, int(got), int(k))
+		}
+	}
+
+	var k ValueKind
+	if err := k.UnmarshalText([]byte("float")); err == nil {
+		t.Errorf("unknown name decoded as %d", int(k))
+	}
+}
+
+func TestIssueJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue Issue
+		json  string
+	}{
+		{
+			name:  "type mismatch",
+			issue: Issue{Path: fields.Path{}.Field("a").Index(1), Code: CodeTypeMismatch, Expected: KindString, Actual: KindNumber, Message: "m"},
+			json:  `{"path":"/a/1","code":"type.mismatch","expected":"string","actual":"number","message":"m"}`,
+		},
+		{
+			name:  "unknown field",
+			issue: Issue{Path: fields.Path{}.Field("x"), Code: CodeUnknownField, Allowed: []string{"a", "b"}, Message: "m"},
+			json:  `{"path":"/x","code":"field.unknown","allowed":["a","b"],"message":"m"}`,
+		},
+		{
+			name:  "missing field",
+			issue: Issue{Path: fields.Path{}.Field("a"), Code: CodeMissingField, Message: "m"},
+			json:  `{"path":"/a","code":"field.missing","message":"m"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.issue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.json {
+				t.Errorf("got %s, want %s", data, test.json)
+			}
+
+			var got Issue
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.issue) {
+				t.Errorf("got %+v, want %+v", got, test.issue)
+			}
+		})
+	}
+
+	var issue Issue
+	if err := json.Unmarshal([]byte(`{"path":"","code":"field.extra","message":"m"}`), &issue); err == nil {
+		t.Error("unknown code was decoded")
+	}
+}
@@ -1,6 +1,12 @@
 package codec
 
-import "github.com/maketaio/openapi/runtime/fields"
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/maketaio/openapi/runtime/fields"
+)
 
 type Code int
 
@@ -35,3 +41,67 @@ type Issue struct {
 func (i *Issue) Error() string {
 	return i.Message
 }
+
+// TypeMismatch converts a type error reported by encoding/json to an issue. The dotted field names
+// of err become property segments of the path.
+func TypeMismatch(err *json.UnmarshalTypeError) *Issue {
+	var path fields.Path
+	if err.Field != "" {
+		for _, name := range strings.Split(err.Field, ".") {
+			path = path.Field(name)
+		}
+	}
+
+	return &Issue{
+		Path:     path,
+		Code:     CodeTypeMismatch,
+		Expected: goKind(err.Type),
+		Actual:   jsonKind(err.Value),
+		Message:  err.Error(),
+	}
+}
+
+func goKind(t reflect.Type) ValueKind {
+	if t == nil {
+		return KindAny
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return KindString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return KindInteger
+	case reflect.Float32, reflect.Float64:
+		return KindNumber
+	case reflect.Bool:
+		return KindBoolean
+	case reflect.Struct, reflect.Map:
+		return KindObject
+	case reflect.Slice, reflect.Array:
+		return KindArray
+	}
+	return KindAny
+}
+
+// jsonKind maps the value description of json.UnmarshalTypeError to a value kind.
+func jsonKind(value string) ValueKind {
+	switch value {
+	case "string":
+		return KindString
+	case "bool":
+		return KindBoolean
+	case "object":
+		return KindObject
+	case "array":
+		return KindArray
+	case "null":
+		return KindNull
+	}
+
+	// Numbers are described as "number" or "number <literal>".
+	if value == "number" || strings.HasPrefix(value, "number ") {
+		return KindNumber
+	}
+	return KindAny
+}
@@ -0,0 +1,650 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/maketaio/openapi/runtime/fields"
+)
+
+// maxDepth bounds the nesting of skipped values, matching the limit of encoding/json.
+const maxDepth = 10000
+
+// Decoder reads a JSON document in a single pass. Generated UnmarshalJSON methods use it to read
+// objects member by member instead of going through reflection.
+//
+// Type mismatches are reported as *Issue with the path of the offending value, so they can be
+// told apart from syntax errors.
+type Decoder struct {
+	data []byte
+	pos  int
+
+	// path is the path of the object or array being read, and seg the segment of the member or
+	// item being read, if any. The path of the current value is only built when an issue is
+	// reported.
+	path   fields.Path
+	seg    fields.Segment
+	hasSeg bool
+}
+
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// Path returns the path of the value about to be read.
+func (d *Decoder) Path() fields.Path {
+	if !d.hasSeg {
+		return d.path
+	}
+	if d.seg.Kind == fields.SegmentIndex {
+		return d.path.Index(d.seg.Index)
+	}
+	return d.path.Field(d.seg.Name)
+}
+
+// Kind returns the kind of the next value without consuming it. Numbers are always reported as
+// KindNumber, and KindAny is returned when the next byte cannot start a value.
+func (d *Decoder) Kind() ValueKind {
+	switch c := d.peek(); {
+	case c == '"':
+		return KindString
+	case c == '{':
+		return KindObject
+	case c == '[':
+		return KindArray
+	case c == 't', c == 'f':
+		return KindBoolean
+	case c == 'n':
+		return KindNull
+	case c == '-', c >= '0' && c <= '9':
+		return KindNumber
+	}
+	return KindAny
+}
+
+// Null consumes the next value and reports true if it is null. Otherwise it consumes nothing.
+func (d *Decoder) Null() bool {
+	return d.literal("null")
+}
+
+// Object reads an object, calling fn for each member with the decoder positioned on its value. fn
+// must consume the value, e.g. with Skip.
+func (d *Decoder) Object(fn func(key string) error) error {
+	if k := d.Kind(); k != KindObject {
+		return d.unexpected(KindObject, k)
+	}
+	d.pos++
+
+	path, seg, hasSeg := d.path, d.seg, d.hasSeg
+	defer func() {
+		d.path, d.seg, d.hasSeg = path, seg, hasSeg
+	}()
+
+	d.path, d.hasSeg = d.Path(), false
+	if d.peek() == '}' {
+		d.pos++
+		return nil
+	}
+
+	for {
+		if d.peek() != '"' {
+			return d.syntaxError("expected object key")
+		}
+
+		name, err := d.readString()
+		if err != nil {
+			return err
+		}
+
+		if d.peek() != ':' {
+			return d.syntaxError("expected colon after object key")
+		}
+		d.pos++
+
+		d.seg, d.hasSeg = fields.Segment{Kind: fields.SegmentProperty, Name: name}, true
+		if err := fn(name); err != nil {
+			return err
+		}
+
+		switch d.peek() {
+		case ',':
+			d.pos++
+		case '}':
+			d.pos++
+			return nil
+		default:
+			return d.syntaxError("expected comma or closing brace after object value")
+		}
+	}
+}
+
+// Array reads an array, calling fn for each item with the decoder positioned on it. fn must consume
+// the item, e.g. with Skip.
+func (d *Decoder) Array(fn func(i int) error) error {
+	if k := d.Kind(); k != KindArray {
+		return d.unexpected(KindArray, k)
+	}
+	d.pos++
+
+	path, seg, hasSeg := d.path, d.seg, d.hasSeg
+	defer func() {
+		d.path, d.seg, d.hasSeg = path, seg, hasSeg
+	}()
+
+	d.path, d.hasSeg = d.Path(), false
+	if d.peek() == ']' {
+		d.pos++
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		d.seg, d.hasSeg = fields.Segment{Kind: fields.SegmentIndex, Index: i}, true
+		if err := fn(i); err != nil {
+			return err
+		}
+
+		switch d.peek() {
+		case ',':
+			d.pos++
+		case ']':
+			d.pos++
+			return nil
+		default:
+			return d.syntaxError("expected comma or closing bracket after array item")
+		}
+	}
+}
+
+// Skip consumes the next value.
+func (d *Decoder) Skip() error {
+	_, err := d.Raw()
+	return err
+}
+
+// Raw consumes the next value and returns its bytes. The result aliases the input.
+func (d *Decoder) Raw() ([]byte, error) {
+	d.skipSpace()
+	start := d.pos
+	if err := d.skipValue(0); err != nil {
+		return nil, err
+	}
+	return d.data[start:d.pos], nil
+}
+
+// End reports an error if anything but whitespace follows the values read so far.
+func (d *Decoder) End() error {
+	if d.skipSpace(); d.pos < len(d.data) {
+		return d.syntaxError("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// String reads a JSON string into v.
+func String[T ~string](d *Decoder, v *T) error {
+	if k := d.Kind(); k != KindString {
+		return d.unexpected(KindString, k)
+	}
+
+	s, err := d.readString()
+	if err != nil {
+		return err
+	}
+	*v = T(s)
+	return nil
+}
+
+// Int reads a JSON number into v. Numbers with a fraction or an exponent, and numbers that do not
+// fit in T, are reported as type mismatches, like encoding/json does.
+func Int[T ~int32 | ~int64](d *Decoder, v *T) error {
+	if k := d.Kind(); k != KindNumber {
+		return d.unexpected(KindInteger, k)
+	}
+
+	lit, err := d.scanNumber()
+	if err != nil {
+		return err
+	}
+
+	n, ok := parseInt(lit)
+	if !ok || int64(T(n)) != n {
+		return d.mismatch(KindInteger, KindNumber)
+	}
+	*v = T(n)
+	return nil
+}
+
+// Float reads a JSON number into v.
+func Float[T ~float64](d *Decoder, v *T) error {
+	if k := d.Kind(); k != KindNumber {
+		return d.unexpected(KindNumber, k)
+	}
+
+	lit, err := d.scanNumber()
+	if err != nil {
+		return err
+	}
+
+	f, err := strconv.ParseFloat(string(lit), 64)
+	if err != nil {
+		return d.mismatch(KindNumber, KindNumber)
+	}
+	*v = T(f)
+	return nil
+}
+
+// Bool reads a JSON boolean into v.
+func Bool[T ~bool](d *Decoder, v *T) error {
+	switch {
+	case d.literal("true"):
+		*v = true
+	case d.literal("false"):
+		*v = false
+	default:
+		return d.unexpected(KindBoolean, d.Kind())
+	}
+	return nil
+}
+
+// Unmarshal reads the next value with v.UnmarshalJSON. Issues it reports are moved under the path
+// of the value.
+func Unmarshal(d *Decoder, v json.Unmarshaler) error {
+	raw, err := d.Raw()
+	if err != nil {
+		return err
+	}
+	return d.nest(v.UnmarshalJSON(raw))
+}
+
+// Any reads the next value into v using encoding/json. It is the fallback for values without a
+// dedicated function.
+func Any(d *Decoder, v any) error {
+	raw, err := d.Raw()
+	if err != nil {
+		return err
+	}
+	return d.nest(json.Unmarshal(raw, v))
+}
+
+// nest moves the location reported by err, which was relative to the current value, under the path
+// of the current value.
+func (d *Decoder) nest(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	path := d.Path()
+	if len(path) == 0 {
+		return err
+	}
+
+	var issue *Issue
+	if errors.As(err, &issue) {
+		nested := *issue
+		nested.Path = append(slices.Clip(path), issue.Path...)
+		return &nested
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		nested := TypeMismatch(typeErr)
+		nested.Path = append(slices.Clip(path), nested.Path...)
+		return nested
+	}
+
+	return err
+}
+
+// UnknownField returns the issue for an object member that is not allowed, listing the names that
+// are.
+func (d *Decoder) UnknownField(allowed ...string) error {
+	return &Issue{
+		Path:    d.Path(),
+		Code:    CodeUnknownField,
+		Allowed: allowed,
+		Message: "unknown field " + strconv.Quote(d.seg.Name),
+	}
+}
+
+func (d *Decoder) mismatch(expected, actual ValueKind) error {
+	return &Issue{
+		Path:     d.Path(),
+		Code:     CodeTypeMismatch,
+		Expected: expected,
+		Actual:   actual,
+		Message:  "expected " + expected.String() + ", got " + actual.String(),
+	}
+}
+
+// unexpected reports a value of kind actual where expected was wanted. Bytes that cannot start a
+// value are a syntax error rather than a mismatch.
+func (d *Decoder) unexpected(expected, actual ValueKind) error {
+	if actual == KindAny {
+		return d.syntaxError("expected value")
+	}
+	return d.mismatch(expected, actual)
+}
+
+func (d *Decoder) syntaxError(msg string) error {
+	if d.pos >= len(d.data) {
+		msg = "unexpected end of JSON input"
+	}
+	return fmt.Errorf("codec: invalid JSON at offset %d: %s", d.pos, msg)
+}
+
+func (d *Decoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek skips whitespace and returns the next byte, or 0 at the end of the input.
+func (d *Decoder) peek() byte {
+	d.skipSpace()
+	if d.pos >= len(d.data) {
+		return 0
+	}
+	return d.data[d.pos]
+}
+
+// literal consumes lit if it is the next value.
+func (d *Decoder) literal(lit string) bool {
+	d.skipSpace()
+	if !bytes.HasPrefix(d.data[d.pos:], []byte(lit)) {
+		return false
+	}
+	d.pos += len(lit)
+	return true
+}
+
+func (d *Decoder) skipValue(depth int) error {
+	if depth > maxDepth {
+		return d.syntaxError("exceeded max depth")
+	}
+
+	switch d.Kind() {
+	case KindString:
+		_, err := d.scanString()
+		return err
+	case KindNumber:
+		_, err := d.scanNumber()
+		return err
+	case KindBoolean:
+		if d.literal("true") || d.literal("false") {
+			return nil
+		}
+	case KindNull:
+		if d.literal("null") {
+			return nil
+		}
+	case KindArray:
+		d.pos++
+		if d.peek() == ']' {
+			d.pos++
+			return nil
+		}
+
+		for {
+			if err := d.skipValue(depth + 1); err != nil {
+				return err
+			}
+
+			switch d.peek() {
+			case ',':
+				d.pos++
+			case ']':
+				d.pos++
+				return nil
+			default:
+				return d.syntaxError("expected comma or closing bracket after array value")
+			}
+		}
+	case KindObject:
+		d.pos++
+		if d.peek() == '}' {
+			d.pos++
+			return nil
+		}
+
+		for {
+			if d.peek() != '"' {
+				return d.syntaxError("expected object key")
+			}
+			if _, err := d.scanString(); err != nil {
+				return err
+			}
+
+			if d.peek() != ':' {
+				return d.syntaxError("expected colon after object key")
+			}
+			d.pos++
+
+			if err := d.skipValue(depth + 1); err != nil {
+				return err
+			}
+
+			switch d.peek() {
+			case ',':
+				d.pos++
+			case '}':
+				d.pos++
+				return nil
+			default:
+				return d.syntaxError("expected comma or closing brace after object value")
+			}
+		}
+	}
+
+	return d.syntaxError("expected value")
+}
+
+// scanString consumes the string starting at the current position and reports whether it contains
+// escapes.
+func (d *Decoder) scanString() (escaped bool, err error) {
+	d.pos++ // opening quote
+	for d.pos < len(d.data) {
+		switch c := d.data[d.pos]; {
+		case c == '"':
+			d.pos++
+			return escaped, nil
+		case c == '\\':
+			escaped = true
+			if d.pos+1 >= len(d.data) {
+				d.pos = len(d.data)
+				return false, d.syntaxError("")
+			}
+
+			switch d.data[d.pos+1] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				d.pos += 2
+			case 'u':
+				if d.pos+6 > len(d.data) || !isHex(d.data[d.pos+2:d.pos+6]) {
+					return false, d.syntaxError("invalid \\u escape in string")
+				}
+				d.pos += 6
+			default:
+				return false, d.syntaxError("invalid escape in string")
+			}
+		case c < 0x20:
+			return false, d.syntaxError("invalid control character in string")
+		default:
+			d.pos++
+		}
+	}
+	return false, d.syntaxError("")
+}
+
+// readString consumes a string and returns its value. Invalid UTF-8 is replaced with U+FFFD.
+func (d *Decoder) readString() (string, error) {
+	start := d.pos
+	escaped, err := d.scanString()
+	if err != nil {
+		return "", err
+	}
+
+	raw := d.data[start+1 : d.pos-1]
+	var s string
+	if escaped {
+		s = unescape(raw)
+	} else {
+		s = string(raw)
+	}
+
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "\uFFFD")
+	}
+	return s, nil
+}
+
+// scanNumber consumes a number and returns its literal.
+func (d *Decoder) scanNumber() ([]byte, error) {
+	start := d.pos
+	digits := func() int {
+		n := 0
+		for d.pos < len(d.data) && d.data[d.pos] >= '0' && d.data[d.pos] <= '9' {
+			d.pos++
+			n++
+		}
+		return n
+	}
+
+	if d.data[d.pos] == '-' {
+		d.pos++
+	}
+
+	if d.pos < len(d.data) && d.data[d.pos] == '0' {
+		d.pos++
+	} else if digits() == 0 {
+		return nil, d.syntaxError("invalid number")
+	}
+
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		d.pos++
+		if digits() == 0 {
+			return nil, d.syntaxError("invalid number")
+		}
+	}
+
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if digits() == 0 {
+			return nil, d.syntaxError("invalid number")
+		}
+	}
+
+	return d.data[start:d.pos], nil
+}
+
+// parseInt parses a number literal that has neither a fraction nor an exponent.
+func parseInt(lit []byte) (int64, bool) {
+	neg := lit[0] == '-'
+	if neg {
+		lit = lit[1:]
+	}
+
+	var n uint64
+	for _, c := range lit {
+		if c < '0' || c > '9' || n > (1<<63)/10 {
+			return 0, false
+		}
+		n = n*10 + uint64(c-'0')
+	}
+
+	if neg {
+		if n > -math.MinInt64 {
+			return 0, false
+		}
+		return -int64(n), true
+	}
+
+	if n > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+func unescape(raw []byte) string {
+	b := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); {
+		if raw[i] != '\\' {
+			b = append(b, raw[i])
+			i++
+			continue
+		}
+
+		switch c := raw[i+1]; c {
+		case 'b':
+			b = append(b, '\b')
+		case 'f':
+			b = append(b, '\f')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case 'u':
+			r := hexRune(raw[i+2 : i+6])
+			i += 6
+			if utf16.IsSurrogate(r) {
+				r2 := rune(-1)
+				if i+6 <= len(raw) && raw[i] == '\\' && raw[i+1] == 'u' {
+					r2 = hexRune(raw[i+2 : i+6])
+				}
+
+				if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+					r = dec
+					i += 6
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			b = utf8.AppendRune(b, r)
+			continue
+		default:
+			b = append(b, c)
+		}
+		i += 2
+	}
+	return string(b)
+}
+
+func isHex(b []byte) bool {
+	for _, c := range b {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func hexRune(b []byte) rune {
+	var r rune
+	for _, c := range b {
+		r <<= 4
+		switch {
+		case '0' <= c && c <= '9':
+			r |= rune(c - '0')
+		case 'a' <= c && c <= 'f':
+			r |= rune(c - 'a' + 10)
+		default:
+			r |= rune(c - 'A' + 10)
+		}
+	}
+	return r
+}
@@ -0,0 +1,115 @@
+package codec
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNestTypeMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"value", `{"a.b": "x"}`, "/a.b"},
+		{"field", `{"a.b": {"n": "x"}}`, "/a.b/n"},
+		{"escaped", `{"a/b": {"n": "x"}}`, "/a~1b/n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDecoder([]byte(test.input))
+			err := d.Object(func(key string) error {
+				if d.Kind() == KindObject {
+					var v struct {
+						N int `json:"n"`
+					}
+					return Any(d, &v)
+				}
+				var n int
+				return Any(d, &n)
+			})
+
+			var issue *Issue
+			if !errors.As(err, &issue) {
+				t.Fatalf("got %v, want an issue", err)
+			}
+			if issue.Code != CodeTypeMismatch {
+				t.Errorf("got code %v, want a type mismatch", issue.Code)
+			}
+			if got := issue.Path.JSONPointer(); got != test.want {
+				t.Errorf("got path %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestArray(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   []int64
+		issue  bool
+		path   string // JSON Pointer of the issue
+		syntax bool
+	}{
+		{name: "empty", input: ` [ ] `},
+		{name: "items", input: `[1, 2 ,3]`, want: []int64{1, 2, 3}},
+		{name: "mismatch", input: `[1, "x"]`, issue: true, path: "/1"},
+		{name: "nested mismatch", input: `[[1], [2, 3.5]]`, issue: true, path: "/1/1"},
+		{name: "member of item", input: `[{"n": 1}, {"n": true}]`, issue: true, path: "/1/n"},
+		{name: "not an array", input: `{}`, issue: true},
+		{name: "missing comma", input: `[1 2]`, syntax: true},
+		{name: "unterminated", input: `[1,`, syntax: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDecoder([]byte(test.input))
+
+			var got []int64
+			var read func() error
+			read = func() error {
+				switch d.Kind() {
+				case KindArray:
+					return d.Array(func(int) error { return read() })
+				case KindObject:
+					return d.Object(func(string) error { return read() })
+				}
+				var n int64
+				if err := Int(d, &n); err != nil {
+					return err
+				}
+				got = append(got, n)
+				return nil
+			}
+
+			err := d.Array(func(int) error { return read() })
+			if err == nil {
+				err = d.End()
+			}
+
+			var issue *Issue
+			isIssue := errors.As(err, &issue)
+
+			switch {
+			case test.syntax:
+				if err == nil || isIssue {
+					t.Errorf("got %v, want a syntax error", err)
+				}
+			case test.issue:
+				if !isIssue {
+					t.Fatalf("got %v, want an issue", err)
+				}
+				if got := issue.Path.JSONPointer(); got != test.path {
+					t.Errorf("got path %q, want %q", got, test.path)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case !slices.Equal(got, test.want):
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package codec
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Encoder writes a JSON document in a single pass. Generated MarshalJSON methods use it to write
+// objects field by field instead of going through reflection. The zero value is ready to use.
+//
+// Values are separated automatically: a value written after another value at the same level is
+// preceded by a comma, and a value written after Key is not.
+type Encoder struct {
+	buf   []byte
+	comma bool
+	err   error
+}
+
+// Bytes returns the encoded document, or the first error encountered while encoding it.
+func (e *Encoder) Bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// BeginObject starts an object.
+func (e *Encoder) BeginObject() {
+	e.sep()
+	e.buf = append(e.buf, '{')
+	e.comma = false
+}
+
+// EndObject ends the current object.
+func (e *Encoder) EndObject() {
+	e.buf = append(e.buf, '}')
+	e.comma = true
+}
+
+// Key writes the name of the next object member.
+func (e *Encoder) Key(k string) {
+	e.sep()
+	e.buf = appendString(e.buf, k)
+	e.buf = append(e.buf, ':')
+	e.comma = false
+}
+
+func (e *Encoder) String(s string) {
+	e.sep()
+	e.buf = appendString(e.buf, s)
+	e.comma = true
+}
+
+func (e *Encoder) Int(n int64) {
+	e.sep()
+	e.buf = strconv.AppendInt(e.buf, n, 10)
+	e.comma = true
+}
+
+// Float writes f the way encoding/json does. NaN and infinities cannot be encoded and make Bytes
+// return an error.
+func (e *Encoder) Float(f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		e.fail(&json.UnsupportedValueError{
+			Value: reflect.ValueOf(f),
+			Str:   strconv.FormatFloat(f, 'g', -1, 64),
+		})
+		return
+	}
+
+	e.sep()
+	e.buf = appendFloat(e.buf, f)
+	e.comma = true
+}
+
+func (e *Encoder) Bool(b bool) {
+	e.sep()
+	e.buf = strconv.AppendBool(e.buf, b)
+	e.comma = true
+}
+
+func (e *Encoder) Null() {
+	e.sep()
+	e.buf = append(e.buf, "null"...)
+	e.comma = true
+}
+
+// Marshal writes the output of m.MarshalJSON as is. It is meant for generated types, whose output
+// is known to be compact and valid.
+func (e *Encoder) Marshal(m json.Marshaler) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		e.fail(err)
+		return
+	}
+
+	e.sep()
+	e.buf = append(e.buf, data...)
+	e.comma = true
+}
+
+// Value writes v using encoding/json. It is the fallback for values without a dedicated method.
+func (e *Encoder) Value(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		e.fail(err)
+		return
+	}
+
+	e.sep()
+	e.buf = append(e.buf, data...)
+	e.comma = true
+}
+
+func (e *Encoder) sep() {
+	if e.comma {
+		e.buf = append(e.buf, ',')
+	}
+}
+
+func (e *Encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// appendFloat formats f like encoding/json: exponent notation is only used for very small and very
+// large magnitudes, and exponents are written without leading zeros.
+func appendFloat(b []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString quotes s like encoding/json, including its escaping of HTML characters, U+2028 and
+// U+2029. Invalid UTF-8 is replaced with U+FFFD.
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
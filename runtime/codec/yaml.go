@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Types whose JSON representation cannot be described with yaml struct tags, such as tuples or
+// objects holding raw JSON, implement their YAML methods with MarshalYAML and UnmarshalYAML, which
+// go through the JSON methods instead.
+
+// MarshalYAML returns the YAML node equivalent to the JSON encoding of v. Object members keep the
+// order of the JSON encoding.
+func MarshalYAML(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so the encoding parses as is
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	node := doc.Content[0]
+	resetStyle(node)
+	return node, nil
+}
+
+// resetStyle clears the flow and quoting styles that node and its children got from JSON, so
+// that they are written in the default YAML style.
+func resetStyle(node *yaml.Node) {
+	// Strings keep their tag, so that those looking like another type are still quoted
+	node.Style = 0
+	for _, c := range node.Content {
+		resetStyle(c)
+	}
+}
+
+// UnmarshalYAML decodes node into v through its JSON equivalent.
+func UnmarshalYAML(node *yaml.Node, v any) error {
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
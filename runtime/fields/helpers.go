@@ -0,0 +1,196 @@
+package fields
+
+import (
+	"cmp"
+	"iter"
+	"reflect"
+)
+
+// Wrapper is implemented by Optional, Nullable and OptionalNullable, so the helpers below work with
+// any of them.
+type Wrapper[T any] interface {
+	State() State[T]
+}
+
+// OrElse returns the value of w, or def if w is unset or null.
+func OrElse[T any, W Wrapper[T]](w W, def T) T {
+	if v, ok := w.State().Value(); ok {
+		return v
+	}
+	return def
+}
+
+// Ptr returns a pointer to a copy of the value of w, or nil if w is unset or null.
+func Ptr[T any, W Wrapper[T]](w W) *T {
+	if v, ok := w.State().Value(); ok {
+		return &v
+	}
+	return nil
+}
+
+// FromPtr returns an Optional holding *p, or an unset Optional if p is nil.
+func FromPtr[T any](p *T) Optional[T] {
+	if p == nil {
+		return OptionalUnset[T]()
+	}
+	return OptionalValue(*p)
+}
+
+// NullableFromPtr returns a Nullable holding *p, or null if p is nil.
+func NullableFromPtr[T any](p *T) Nullable[T] {
+	if p == nil {
+		return Null[T]()
+	}
+	return NullableValue(*p)
+}
+
+// Map applies fn to the value of o. An unset o stays unset.
+func Map[T, U any](o Optional[T], fn func(T) U) Optional[U] {
+	if v, ok := o.Get(); ok {
+		return OptionalValue(fn(v))
+	}
+	return OptionalUnset[U]()
+}
+
+// MapNullable applies fn to the value of r. A null r stays null.
+func MapNullable[T, U any](r Nullable[T], fn func(T) U) Nullable[U] {
+	if v, ok := r.Get(); ok {
+		return NullableValue(fn(v))
+	}
+	return Null[U]()
+}
+
+// MapOptionalNullable applies fn to the value of o. An unset or null o stays unset or null.
+func MapOptionalNullable[T, U any](o OptionalNullable[T], fn func(T) U) OptionalNullable[U] {
+	switch v, ok := o.Get(); {
+	case ok:
+		return OptionalNullableValue(fn(v))
+	case o.IsNull():
+		return OptionalNull[U]()
+	}
+	return OptionalNullableUnset[U]()
+}
+
+// Equal reports whether a and b are in the same state and, if set, hold equal values.
+func Equal[T comparable, W Wrapper[T]](a, b W) bool {
+	sa, sb := a.State(), b.State()
+	if sa.IsPresent() != sb.IsPresent() || sa.IsNull() != sb.IsNull() {
+		return false
+	}
+
+	va, _ := sa.Value()
+	vb, _ := sb.Value()
+	return va == vb
+}
+
+// Compare returns -1, 0 or +1 depending on whether a is less than, equal to or greater than b.
+// Unset sorts before null, which sorts before any value. Values are ordered with cmp.Compare.
+func Compare[T cmp.Ordered, W Wrapper[T]](a, b W) int {
+	sa, sb := a.State(), b.State()
+	if c := cmp.Compare(rank(sa), rank(sb)); c != 0 {
+		return c
+	}
+
+	va, _ := sa.Value()
+	vb, _ := sb.Value()
+	return cmp.Compare(va, vb)
+}
+
+func rank[T any](s State[T]) int {
+	switch {
+	case !s.IsPresent():
+		return 0
+	case s.IsNull():
+		return 1
+	}
+	return 2
+}
+
+// IsZero reports whether v is the zero value of its type, using its IsZero method if it has one.
+// Generated code uses it for optional fields represented by their value.
+func IsZero[T any](v T) bool {
+	if z, ok := any(v).(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	return reflect.ValueOf(&v).Elem().IsZero()
+}
+
+// Values returns an iterator over the values of the elements of ws that are set, skipping unset and
+// null elements.
+func Values[T any, W Wrapper[T]](ws []W) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, w := range ws {
+			if v, ok := w.State().Value(); ok && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator that yields the value of o, if set.
+func (o Optional[T]) All() iter.Seq[T] {
+	return o.s.all()
+}
+
+// All returns an iterator that yields the value of r, if not null.
+func (r Nullable[T]) All() iter.Seq[T] {
+	return r.s.all()
+}
+
+// All returns an iterator that yields the value of o, if set and not null.
+func (o OptionalNullable[T]) All() iter.Seq[T] {
+	return o.s.all()
+}
+
+func (s State[T]) all() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if v, ok := s.Value(); ok {
+			yield(v)
+		}
+	}
+}
+
+// OptionalNullable converts o, keeping an unset o unset.
+func (o Optional[T]) OptionalNullable() OptionalNullable[T] {
+	return OptionalNullable[T]{s: o.s}
+}
+
+// Nullable converts o, turning an unset o into null.
+func (o Optional[T]) Nullable() Nullable[T] {
+	if v, ok := o.Get(); ok {
+		return NullableValue(v)
+	}
+	return Null[T]()
+}
+
+// OptionalNullable converts r, keeping a null r null.
+func (r Nullable[T]) OptionalNullable() OptionalNullable[T] {
+	if v, ok := r.Get(); ok {
+		return OptionalNullableValue(v)
+	}
+	return OptionalNull[T]()
+}
+
+// Optional converts r, turning a null r into an unset Optional.
+func (r Nullable[T]) Optional() Optional[T] {
+	if v, ok := r.Get(); ok {
+		return OptionalValue(v)
+	}
+	return OptionalUnset[T]()
+}
+
+// Optional converts o, turning null into unset.
+func (o OptionalNullable[T]) Optional() Optional[T] {
+	if v, ok := o.Get(); ok {
+		return OptionalValue(v)
+	}
+	return OptionalUnset[T]()
+}
+
+// Nullable converts o, turning unset into null.
+func (o OptionalNullable[T]) Nullable() Nullable[T] {
+	if v, ok := o.Get(); ok {
+		return NullableValue(v)
+	}
+	return Null[T]()
+}
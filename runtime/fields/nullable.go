@@ -30,6 +30,13 @@ func (r Nullable[T]) IsNull() bool {
 	return r.s.IsNull()
 }
 
+func (r Nullable[T]) Get() (T, bool) {
+	return r.s.Value()
+}
+
+// Value returns the value held by r, if any.
+//
+// Deprecated: Use Get. Value is kept for compatibility; use SQL to pass r to database/sql.
 func (r Nullable[T]) Value() (T, bool) {
 	return r.s.Value()
 }
@@ -30,6 +30,13 @@ func (o Optional[T]) IsPresent() bool {
 	return o.s.IsPresent()
 }
 
+func (o Optional[T]) Get() (T, bool) {
+	return o.s.Value()
+}
+
+// Value returns the value held by o, if any.
+//
+// Deprecated: Use Get. Value is kept for compatibility; use SQL to pass o to database/sql.
 func (o Optional[T]) Value() (T, bool) {
 	return o.s.Value()
 }
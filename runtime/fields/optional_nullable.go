@@ -46,6 +46,13 @@ func (o OptionalNullable[T]) IsNull() bool {
 	return o.s.IsNull()
 }
 
+func (o OptionalNullable[T]) Get() (T, bool) {
+	return o.s.Value()
+}
+
+// Value returns the value held by o, if any.
+//
+// Deprecated: Use Get. Value is kept for compatibility; use SQL to pass o to database/sql.
 func (o OptionalNullable[T]) Value() (T, bool) {
 	return o.s.Value()
 }
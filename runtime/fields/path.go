@@ -1,13 +1,308 @@
 package fields
 
-import "strings"
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
 
-type Path []string
+type SegmentKind int
 
+const (
+	// SegmentProperty is used for object properties.
+	SegmentProperty SegmentKind = iota
+	// SegmentIndex is used for array and tuple items.
+	SegmentIndex
+	// SegmentKey is used for map keys, i.e. properties that are not declared by the schema.
+	SegmentKey
+)
+
+// Segment is a single step of a Path.
+type Segment struct {
+	Kind SegmentKind
+	// Name is the property name or map key. It is empty for indexes.
+	Name string
+	// Index is the item index. It is zero for properties and keys.
+	Index int
+}
+
+// Path is the location of a value inside a document, e.g. the value that failed validation.
+type Path []Segment
+
+// Field returns p followed by the property name. It never modifies the backing array of p, so
+// sibling paths built from the same parent are independent.
+func (p Path) Field(name string) Path {
+	return p.with(Segment{Kind: SegmentProperty, Name: name})
+}
+
+// Index returns p followed by the item index i.
+func (p Path) Index(i int) Path {
+	return p.with(Segment{Kind: SegmentIndex, Index: i})
+}
+
+// Key returns p followed by the map key k.
+func (p Path) Key(k string) Path {
+	return p.with(Segment{Kind: SegmentKey, Name: k})
+}
+
+func (p Path) with(seg Segment) Path {
+	return append(slices.Clip(p), seg)
+}
+
+// String returns a human readable form of p, e.g. items[3].name or labels["en.US"].
 func (p Path) String() string {
-	return strings.Join(p, ".")
+	var b strings.Builder
+	for i, seg := range p {
+		switch seg.Kind {
+		case SegmentProperty:
+			if i > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(seg.Name)
+		case SegmentIndex:
+			b.WriteString("[" + strconv.Itoa(seg.Index) + "]")
+		case SegmentKey:
+			b.WriteString("[" + strconv.Quote(seg.Name) + "]")
+		}
+	}
+	return b.String()
+}
+
+// JSONPointer returns p as an RFC 6901 JSON Pointer, e.g. /items/3/name. The empty path is the
+// empty pointer, which refers to the whole document.
+func (p Path) JSONPointer() string {
+	var b strings.Builder
+	for _, seg := range p {
+		b.WriteString("/")
+		if seg.Kind == SegmentIndex {
+			b.WriteString(strconv.Itoa(seg.Index))
+			continue
+		}
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(seg.Name, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// JSONPath returns p as a normalized JSONPath (RFC 9535), e.g. $['items'][3]['name'].
+func (p Path) JSONPath() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, seg := range p {
+		if seg.Kind == SegmentIndex {
+			b.WriteString("[" + strconv.Itoa(seg.Index) + "]")
+			continue
+		}
+		b.WriteString("['")
+		writeJSONPathName(&b, seg.Name)
+		b.WriteString("']")
+	}
+	return b.String()
+}
+
+// writeJSONPathName writes name escaped as in the single quoted member names of normalized paths:
+// quotes, backslashes and control characters are escaped, everything else is written as is.
+func writeJSONPathName(b *strings.Builder, name string) {
+	for _, c := range name {
+		switch c {
+		case '\'', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, c)
+				continue
+			}
+			b.WriteRune(c)
+		}
+	}
+}
+
+// ParseJSONPointer parses an RFC 6901 JSON Pointer. Pointers do not say whether a token is an
+// index, so tokens that are non-negative integers without leading zeros become indexes and every
+// other token becomes a property.
+func ParseJSONPointer(s string) (Path, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("fields: JSON pointer %q must start with /", s)
+	}
+
+	var p Path
+	for _, tok := range strings.Split(s[1:], "/") {
+		if i, ok := parseIndex(tok); ok {
+			p = append(p, Segment{Kind: SegmentIndex, Index: i})
+			continue
+		}
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(tok, "~0", ""), "~1", ""), "~") {
+			return nil, fmt.Errorf("fields: JSON pointer %q has an invalid escape", s)
+		}
+		p = append(p, Segment{
+			Kind: SegmentProperty,
+			Name: strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~"),
+		})
+	}
+	return p, nil
+}
+
+// ParseJSONPath parses a JSONPath made of member and index selectors, in either dot notation
+// ($.items[3].name) or bracket notation ($['items'][3]['name']). Names become properties.
+func ParseJSONPath(s string) (Path, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("fields: JSONPath %q must start with $", s)
+	}
+
+	var p Path
+	rest := s[1:]
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("fields: JSONPath %q has an empty member name", s)
+			}
+			p = append(p, Segment{Kind: SegmentProperty, Name: name})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "['"), strings.HasPrefix(rest, `["`):
+			name, n, err := parseQuoted(rest[1:])
+			if err != nil || !strings.HasPrefix(rest[1+n:], "]") {
+				return nil, fmt.Errorf("fields: JSONPath %q has an invalid member selector", s)
+			}
+			p = append(p, Segment{Kind: SegmentProperty, Name: name})
+			rest = rest[1+n+1:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			i, ok := parseIndex(rest[1:max(end, 1)])
+			if end == -1 || !ok {
+				return nil, fmt.Errorf("fields: JSONPath %q has an invalid index selector", s)
+			}
+			p = append(p, Segment{Kind: SegmentIndex, Index: i})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("fields: JSONPath %q is not supported", s)
+		}
+	}
+	return p, nil
+}
+
+// MarshalText encodes p as a JSON Pointer.
+func (p Path) MarshalText() ([]byte, error) {
+	return []byte(p.JSONPointer()), nil
+}
+
+// UnmarshalText decodes p from a JSON Pointer, see ParseJSONPointer.
+func (p *Path) UnmarshalText(text []byte) error {
+	res, err := ParseJSONPointer(string(text))
+	if err != nil {
+		return err
+	}
+	*p = res
+	return nil
+}
+
+func parseIndex(tok string) (int, bool) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, false
+	}
+	for _, c := range tok {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(tok)
+	return i, err == nil
+}
+
+// parseQuoted parses a single or double quoted string at the start of s and returns its value
+// and the number of bytes it spans. It accepts the escapes of RFC 9535, including \uXXXX with
+// surrogate pairs.
+func parseQuoted(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == quote {
+			return b.String(), i + 1, nil
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(s) {
+			break
+		}
+		switch s[i] {
+		case '\'', '"', '\\', '/':
+			b.WriteByte(s[i])
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r, n, err := parseUnicodeEscape(s[i-1:])
+			if err != nil {
+				return "", 0, err
+			}
+			b.WriteRune(r)
+			i += n - 2
+		default:
+			return "", 0, fmt.Errorf("invalid escape \\%c", s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// parseUnicodeEscape parses the \uXXXX escape at the start of s, followed by a second one if the
+// first is a high surrogate, and returns the rune and the number of bytes used.
+func parseUnicodeEscape(s string) (rune, int, error) {
+	r, ok := parseHex4(s)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid unicode escape")
+	}
+	if !utf16.IsSurrogate(r) {
+		return r, 6, nil
+	}
+
+	r2, ok := parseHex4(s[6:])
+	if !ok || r >= 0xdc00 {
+		return 0, 0, fmt.Errorf("invalid surrogate pair")
+	}
+	if r = utf16.DecodeRune(r, r2); r == unicode.ReplacementChar {
+		return 0, 0, fmt.Errorf("invalid surrogate pair")
+	}
+	return r, 12, nil
 }
 
-func (p Path) Field(s string) Path {
-	return append(p, s)
+// parseHex4 parses the \uXXXX escape at the start of s.
+func parseHex4(s string) (rune, bool) {
+	if len(s) < 6 || s[0] != '\\' || s[1] != 'u' {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s[2:6], 16, 16)
+	return rune(v), err == nil
 }
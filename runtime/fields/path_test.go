@@ -0,0 +1,159 @@
+package fields
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONPointer(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Path
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "root property", in: "/", want: Path{}.Field("")},
+		{name: "properties and index", in: "/items/3/name", want: Path{}.Field("items").Index(3).Field("name")},
+		{name: "escapes", in: "/a~1b/c~0d/~01", want: Path{}.Field("a/b").Field("c~d").Field("~1")},
+		{name: "leading zero", in: "/01", want: Path{}.Field("01")},
+		{name: "negative", in: "/-1", want: Path{}.Field("-1")},
+		{name: "unicode", in: "/café/☃", want: Path{}.Field("café").Field("☃")},
+		{name: "no leading slash", in: "items", wantErr: true},
+		{name: "invalid escape", in: "/a~2", wantErr: true},
+		{name: "trailing tilde", in: "/a~", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseJSONPointer(test.in)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %#v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Path
+		wantErr bool
+	}{
+		{name: "root", in: "$", want: nil},
+		{name: "dot notation", in: "$.items[3].name", want: Path{}.Field("items").Index(3).Field("name")},
+		{name: "bracket notation", in: "$['items'][3]['name']", want: Path{}.Field("items").Index(3).Field("name")},
+		{name: "double quotes", in: `$["a'b"]`, want: Path{}.Field("a'b")},
+		{name: "escaped quote and backslash", in: `$['a\'b\\c']`, want: Path{}.Field(`a'b\c`)},
+		{name: "control escapes", in: `$['\b\f\n\r\t\/']`, want: Path{}.Field("\b\f\n\r\t/")},
+		{name: "unicode escape", in: `$['é\u0001']`, want: Path{}.Field("é\x01")},
+		{name: "surrogate pair", in: `$['\ud83d\ude00']`, want: Path{}.Field("😀")},
+		{name: "brackets and dots in names", in: `$['a.b']['[0]']`, want: Path{}.Field("a.b").Field("[0]")},
+		{name: "empty name", in: "$['']", want: Path{}.Field("")},
+		{name: "no root", in: "items", wantErr: true},
+		{name: "empty member", in: "$..a", wantErr: true},
+		{name: "unterminated", in: "$['a", wantErr: true},
+		{name: "missing bracket", in: "$['a'", wantErr: true},
+		{name: "invalid escape", in: `$['\q']`, wantErr: true},
+		{name: "short unicode escape", in: `$['\u00e']`, wantErr: true},
+		{name: "lone high surrogate", in: `$['\ud83d']`, wantErr: true},
+		{name: "lone low surrogate", in: `$['\ude00\ude00']`, wantErr: true},
+		{name: "leading zero index", in: "$[01]", wantErr: true},
+		{name: "wildcard", in: "$[*]", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseJSONPath(test.in)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %#v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPathFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     Path
+		str      string
+		pointer  string
+		jsonPath string
+	}{
+		{"root", nil, "", "", "$"},
+		{"nested", Path{}.Field("items").Index(3).Field("name"), "items[3].name", "/items/3/name", "$['items'][3]['name']"},
+		{"key", Path{}.Field("labels").Key("en.US"), `labels["en.US"]`, "/labels/en.US", "$['labels']['en.US']"},
+		{"pointer escapes", Path{}.Field("a/b").Field("c~d"), "a/b.c~d", "/a~1b/c~0d", "$['a/b']['c~d']"},
+		{"quotes", Path{}.Field(`it's "x"\`), `it's "x"\`, `/it's "x"\`, `$['it\'s "x"\\']`},
+		{"control characters", Path{}.Field("a\tb\n\x01\x7f"), "a\tb\n\x01\x7f", "/a\tb\n\x01\x7f", "$['a\\tb\\n\\u0001\x7f']"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.path.String(); got != test.str {
+				t.Errorf("String() = %q, want %q", got, test.str)
+			}
+			if got := test.path.JSONPointer(); got != test.pointer {
+				t.Errorf("JSONPointer() = %q, want %q", got, test.pointer)
+			}
+			if got := test.path.JSONPath(); got != test.jsonPath {
+				t.Errorf("JSONPath() = %q, want %q", got, test.jsonPath)
+			}
+
+			text, err := test.path.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(text) != test.pointer {
+				t.Errorf("MarshalText() = %q, want %q", text, test.pointer)
+			}
+
+			// Both notations parse keys as properties
+			want := make(Path, len(test.path))
+			for i, seg := range test.path {
+				if seg.Kind == SegmentKey {
+					seg.Kind = SegmentProperty
+				}
+				want[i] = seg
+			}
+			if len(want) == 0 {
+				want = nil
+			}
+
+			got, err := ParseJSONPath(test.path.JSONPath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseJSONPath(JSONPath()) = %#v, want %#v", got, want)
+			}
+
+			var unmarshaled Path
+			if err := unmarshaled.UnmarshalText(text); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(unmarshaled, want) {
+				t.Errorf("UnmarshalText(MarshalText()) = %#v, want %#v", unmarshaled, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package fields
+
+import (
+	"database/sql"
+	"database/sql/driver"
+)
+
+// SQL adapts a wrapper to database/sql. It implements sql.Scanner, storing into the wrapper it was
+// made from, and driver.Valuer. The wrappers cannot implement driver.Valuer themselves since their
+// Value method returns the held value.
+//
+//	err := row.Scan(&u.ID, u.Nickname.SQL())
+//	_, err = db.Exec("UPDATE users SET nickname = $1", u.Nickname.SQL())
+type SQL[T any] struct {
+	s         *State[T]
+	nullIsSet bool
+}
+
+// scan decodes a database value into s. NULL is stored as null when nullIsSet is true and as
+// unset otherwise. Non-NULL values are converted the same way database/sql converts into *T,
+// including through sql.Scanner.
+func (s *State[T]) scan(src any, nullIsSet bool) error {
+	var n sql.Null[T]
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+
+	switch {
+	case n.Valid:
+		s.Set(n.V)
+	case nullIsSet:
+		s.SetNull()
+	default:
+		s.Unset()
+	}
+	return nil
+}
+
+// value encodes s as a database value. Unset and null are both NULL.
+func (s State[T]) value() (driver.Value, error) {
+	v, ok := s.Value()
+	return sql.Null[T]{V: v, Valid: ok}.Value()
+}
+
+// SQL returns an adapter scanning into o, where NULL leaves o unset, and writing o, where unset is
+// NULL.
+func (o *Optional[T]) SQL() SQL[T] {
+	return SQL[T]{s: &o.s}
+}
+
+// SQL returns an adapter scanning into r, where NULL sets r to null, and writing r, where null is
+// NULL.
+func (r *Nullable[T]) SQL() SQL[T] {
+	return SQL[T]{s: &r.s, nullIsSet: true}
+}
+
+// SQL returns an adapter scanning into o and writing o. Databases have a single NULL, so NULL sets
+// o to null rather than unset, and both unset and null are NULL.
+func (o *OptionalNullable[T]) SQL() SQL[T] {
+	return SQL[T]{s: &o.s, nullIsSet: true}
+}
+
+// Scan implements sql.Scanner.
+func (a SQL[T]) Scan(src any) error {
+	return a.s.scan(src, a.nullIsSet)
+}
+
+// Value implements driver.Valuer.
+func (a SQL[T]) Value() (driver.Value, error) {
+	return a.s.value()
+}
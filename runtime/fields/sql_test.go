@@ -0,0 +1,143 @@
+package fields
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		scan    func(src any) (string, error)
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "optional NULL",
+			src:  nil,
+			scan: scanInto[Optional[int64], int64],
+			want: "unset",
+		},
+		{
+			name: "optional value",
+			src:  int64(42),
+			scan: scanInto[Optional[int64], int64],
+			want: "42",
+		},
+		{
+			name: "nullable NULL",
+			src:  nil,
+			scan: scanInto[Nullable[string], string],
+			want: "null",
+		},
+		{
+			name: "nullable value",
+			src:  []byte("abc"),
+			scan: scanInto[Nullable[string], string],
+			want: "abc",
+		},
+		{
+			name: "optional nullable NULL",
+			src:  nil,
+			scan: scanInto[OptionalNullable[bool], bool],
+			want: "null",
+		},
+		{
+			name: "optional nullable value",
+			src:  true,
+			scan: scanInto[OptionalNullable[bool], bool],
+			want: "true",
+		},
+		{
+			name: "converted",
+			src:  "1.5",
+			scan: scanInto[Optional[float64], float64],
+			want: "1.5",
+		},
+		{
+			name:    "not convertible",
+			src:     "x",
+			scan:    scanInto[Optional[int64], int64],
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.scan(test.src)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		valuer driver.Valuer
+		want   driver.Value
+	}{
+		{"optional unset", ref(OptionalUnset[int64]()).SQL(), nil},
+		{"optional value", ref(OptionalValue[int64](42)).SQL(), int64(42)},
+		{"nullable null", ref(Null[string]()).SQL(), nil},
+		{"nullable value", ref(NullableValue("abc")).SQL(), "abc"},
+		{"optional nullable unset", ref(OptionalNullableUnset[bool]()).SQL(), nil},
+		{"optional nullable null", ref(OptionalNull[bool]()).SQL(), nil},
+		{"optional nullable value", ref(OptionalNullableValue(true)).SQL(), true},
+		{"converted", ref(OptionalValue[int32](7)).SQL(), int64(7)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.valuer.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+// scanInto scans src into a new W through its SQL adapter and describes the result.
+func scanInto[W, T any, P interface {
+	*W
+	SQL() SQL[T]
+	State() State[T]
+}](src any) (string, error) {
+	var w W
+	var scanner sql.Scanner = P(&w).SQL()
+	if err := scanner.Scan(src); err != nil {
+		return "", err
+	}
+	return describe(P(&w).State()), nil
+}
+
+func ref[T any](v T) *T {
+	return &v
+}
+
+// describe returns "unset", "null" or the value held by s, which keeps test tables short.
+func describe[T any](s State[T]) string {
+	if !s.IsPresent() {
+		return "unset"
+	}
+	if v, ok := s.Value(); ok {
+		return fmt.Sprint(v)
+	}
+	return "null"
+}
@@ -0,0 +1,116 @@
+package fields
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// unmarshalText sets s to the value parsed from text. Text cannot express null, so s is always
+// set to a value.
+func (s *State[T]) unmarshalText(text []byte) error {
+	var v T
+	if u, ok := any(&v).(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText(text); err != nil {
+			return err
+		}
+		s.Set(v)
+		return nil
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	str := string(text)
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(str, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(str, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	default:
+		return fmt.Errorf("fields: cannot unmarshal text into %s", rv.Type())
+	}
+
+	s.Set(v)
+	return nil
+}
+
+// marshalText formats the value of s as text. Unset and null values are empty.
+func (s State[T]) marshalText() ([]byte, error) {
+	v, ok := s.Value()
+	if !ok {
+		return []byte{}, nil
+	}
+
+	if m, ok := any(v).(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	switch rv.Kind() {
+	case reflect.String:
+		return []byte(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(nil, rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(nil, rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.AppendFloat(nil, rv.Float(), 'g', -1, rv.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.AppendBool(nil, rv.Bool()), nil
+	}
+	return nil, fmt.Errorf("fields: cannot marshal %s as text", rv.Type())
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It always sets a value.
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	return o.s.unmarshalText(text)
+}
+
+// MarshalText implements encoding.TextMarshaler. An unset value is empty.
+func (o Optional[T]) MarshalText() ([]byte, error) {
+	return o.s.marshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It always sets a value, since text cannot
+// express null.
+func (r *Nullable[T]) UnmarshalText(text []byte) error {
+	return r.s.unmarshalText(text)
+}
+
+// MarshalText implements encoding.TextMarshaler. Null is empty.
+func (r Nullable[T]) MarshalText() ([]byte, error) {
+	return r.s.marshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It always sets a value, since text cannot
+// express null.
+func (o *OptionalNullable[T]) UnmarshalText(text []byte) error {
+	return o.s.unmarshalText(text)
+}
+
+// MarshalText implements encoding.TextMarshaler. Unset and null values are empty.
+func (o OptionalNullable[T]) MarshalText() ([]byte, error) {
+	return o.s.marshalText()
+}
@@ -0,0 +1,128 @@
+package fields
+
+import (
+	"encoding"
+	"testing"
+	"time"
+)
+
+func TestMarshalText(t *testing.T) {
+	tests := []struct {
+		name string
+		m    encoding.TextMarshaler
+		want string
+	}{
+		{"optional unset", OptionalUnset[int](), ""},
+		{"optional value", OptionalValue(42), "42"},
+		{"nullable null", Null[string](), ""},
+		{"nullable value", NullableValue("a b"), "a b"},
+		{"optional nullable unset", OptionalNullableUnset[bool](), ""},
+		{"optional nullable null", OptionalNull[bool](), ""},
+		{"optional nullable value", OptionalNullableValue(true), "true"},
+		{"float", OptionalValue(1.5), "1.5"},
+		{"uint", OptionalValue(uint8(7)), "7"},
+		{"text marshaler", OptionalValue(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), "2024-01-02T03:04:05Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.m.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		decode  func(text []byte) (string, error)
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "optional int",
+			text:   "42",
+			decode: decodeText[Optional[int], int],
+			want:   "42",
+		},
+		{
+			name:   "nullable string",
+			text:   "",
+			decode: decodeText[Nullable[string], string],
+			want:   "",
+		},
+		{
+			name:   "optional nullable bool",
+			text:   "true",
+			decode: decodeText[OptionalNullable[bool], bool],
+			want:   "true",
+		},
+		{
+			name:   "float",
+			text:   "1.5",
+			decode: decodeText[Optional[float64], float64],
+			want:   "1.5",
+		},
+		{
+			name:   "text unmarshaler",
+			text:   "2024-01-02T03:04:05Z",
+			decode: decodeText[Optional[time.Time], time.Time],
+			want:   "2024-01-02 03:04:05 +0000 UTC",
+		},
+		{
+			name:    "invalid int",
+			text:    "x",
+			decode:  decodeText[Optional[int], int],
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			text:    "300",
+			decode:  decodeText[Optional[uint8], uint8],
+			wantErr: true,
+		},
+		{
+			name:    "unsupported kind",
+			text:    "a",
+			decode:  decodeText[Optional[[]int], []int],
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.decode([]byte(test.text))
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// decodeText unmarshals text into a new W and describes the result.
+func decodeText[W, T any, P interface {
+	*W
+	encoding.TextUnmarshaler
+	State() State[T]
+}](text []byte) (string, error) {
+	var w W
+	if err := P(&w).UnmarshalText(text); err != nil {
+		return "", err
+	}
+	return describe(P(&w).State()), nil
+}
@@ -0,0 +1,60 @@
+package fields
+
+import "go.yaml.in/yaml/v4"
+
+// The yaml package never calls UnmarshalYAML for a null node, so an explicit null is set by the
+// struct holding the field instead, see IsYAMLNull. A missing key leaves the field unset, just like
+// with JSON.
+
+func (s *State[T]) unmarshalYAML(node *yaml.Node) error {
+	var v T
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	s.Set(v)
+	return nil
+}
+
+func (s State[T]) marshalYAML() (any, error) {
+	v, ok := s.Value()
+	if !ok {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// IsYAMLNull reports whether node is an explicit YAML null.
+func IsYAMLNull(node *yaml.Node) bool {
+	return node.ShortTag() == "!!null"
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (o *Optional[T]) UnmarshalYAML(node *yaml.Node) error {
+	return o.s.unmarshalYAML(node)
+}
+
+// MarshalYAML implements yaml.Marshaler. An unset value is null unless omitted with omitempty.
+func (o Optional[T]) MarshalYAML() (any, error) {
+	return o.s.marshalYAML()
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (r *Nullable[T]) UnmarshalYAML(node *yaml.Node) error {
+	return r.s.unmarshalYAML(node)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (r Nullable[T]) MarshalYAML() (any, error) {
+	return r.s.marshalYAML()
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (o *OptionalNullable[T]) UnmarshalYAML(node *yaml.Node) error {
+	return o.s.unmarshalYAML(node)
+}
+
+// MarshalYAML implements yaml.Marshaler. Unset and null values are both null, but unset values
+// are omitted with omitempty.
+func (o OptionalNullable[T]) MarshalYAML() (any, error) {
+	return o.s.marshalYAML()
+}
@@ -0,0 +1,108 @@
+package fields
+
+import (
+	"testing"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// yamlDoc holds one field of each wrapper. It sets explicit nulls the way generated types do,
+// since the yaml package does not call UnmarshalYAML for them.
+type yamlDoc struct {
+	Optional         Optional[int]         `yaml:"optional,omitempty"`
+	Nullable         Nullable[int]         `yaml:"nullable"`
+	OptionalNullable OptionalNullable[int] `yaml:"optionalNullable,omitempty"`
+}
+
+func (d *yamlDoc) UnmarshalYAML(node *yaml.Node) error {
+	type alias yamlDoc
+	if err := node.Decode((*alias)(d)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !IsYAMLNull(node.Content[i+1]) {
+			continue
+		}
+		switch node.Content[i].Value {
+		case "nullable":
+			d.Nullable.SetNull()
+		case "optionalNullable":
+			d.OptionalNullable.SetNull()
+		}
+	}
+	return nil
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [3]string
+	}{
+		{
+			name:  "absent",
+			input: "{}",
+			want:  [3]string{"unset", "unset", "unset"},
+		},
+		{
+			name:  "null",
+			input: "optional: null\nnullable: null\noptionalNullable: null\n",
+			want:  [3]string{"unset", "null", "null"},
+		},
+		{
+			name:  "value",
+			input: "optional: 1\nnullable: 2\noptionalNullable: 3\n",
+			want:  [3]string{"1", "2", "3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var d yamlDoc
+			if err := yaml.Unmarshal([]byte(test.input), &d); err != nil {
+				t.Fatal(err)
+			}
+
+			got := [3]string{describe(d.Optional.State()), describe(d.Nullable.State()), describe(d.OptionalNullable.State())}
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  yamlDoc
+		want string
+	}{
+		{
+			name: "absent",
+			doc:  yamlDoc{},
+			want: "nullable: null\n",
+		},
+		{
+			name: "null",
+			doc:  yamlDoc{Nullable: Null[int](), OptionalNullable: OptionalNull[int]()},
+			want: "nullable: null\noptionalNullable: null\n",
+		},
+		{
+			name: "value",
+			doc:  yamlDoc{Optional: OptionalValue(1), Nullable: NullableValue(2), OptionalNullable: OptionalNullableValue(3)},
+			want: "optional: 1\nnullable: 2\noptionalNullable: 3\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := yaml.Marshal(test.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package problem renders validation and codec issues as RFC 9457 problem details.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/maketaio/openapi/runtime/codec"
+	"github.com/maketaio/openapi/runtime/validation"
+)
+
+// ContentType is the media type of problem details documents.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document. Errors is an extension member listing the
+// individual issues that caused the problem.
+type Problem struct {
+	Type     string  `json:"type,omitempty"`
+	Title    string  `json:"title,omitempty"`
+	Status   int     `json:"status,omitempty"`
+	Detail   string  `json:"detail,omitempty"`
+	Instance string  `json:"instance,omitempty"`
+	Errors   []Error `json:"errors,omitempty"`
+}
+
+// Error describes a single issue of a problem.
+type Error struct {
+	// Pointer is a JSON Pointer (RFC 6901) to the offending value in the request body.
+	Pointer string `json:"pointer"`
+	// Code is a stable identifier of the kind of issue, e.g. "str.pattern".
+	Code   string `json:"code"`
+	Detail string `json:"detail,omitempty"`
+	// Params holds the parameters of the issue, e.g. the violated maximum.
+	Params any `json:"params,omitempty"`
+}
+
+// New returns a problem for status with its standard title.
+func New(status int) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+}
+
+// Validation returns a 400 problem listing validation issues.
+func Validation(issues validation.Issues) *Problem {
+	p := New(http.StatusBadRequest)
+	p.Detail = "The request body is invalid."
+	p.Errors = ValidationErrors(issues)
+	return p
+}
+
+// Decode returns a 400 problem for an error returned while decoding a JSON request body. Codec
+// issues, validation reports and type mismatches reported by encoding/json are listed in Errors.
+func Decode(err error) *Problem {
+	p := New(http.StatusBadRequest)
+	p.Detail = "The request body could not be decoded."
+
+	var issue *codec.Issue
+	var typeErr *json.UnmarshalTypeError
+	var report *validation.Report
+	switch {
+	case errors.As(err, &report):
+		p.Errors = append(CodecErrors(report.Decode), ValidationErrors(report.Issues)...)
+	case errors.As(err, &issue):
+		p.Errors = CodecErrors([]*codec.Issue{issue})
+	case errors.As(err, &typeErr):
+		p.Errors = CodecErrors([]*codec.Issue{codec.TypeMismatch(typeErr)})
+	default:
+		p.Detail = "The request body is not valid JSON: " + err.Error()
+	}
+
+	return p
+}
+
+// Write writes p to w as an application/problem+json response with p.Status as the status code.
+func (p *Problem) Write(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// ValidationErrors converts validation issues to problem errors.
+func ValidationErrors(issues validation.Issues) []Error {
+	res := make([]Error, 0, len(issues))
+	for _, issue := range issues {
+		e := Error{
+			Pointer: issue.Path.JSONPointer(),
+			Code:    issue.Code.String(),
+			Detail:  issue.Error(),
+		}
+		if !issue.Params.IsZero() {
+			e.Params = issue.Params
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// CodecErrors converts codec issues to problem errors.
+func CodecErrors(issues []*codec.Issue) []Error {
+	res := make([]Error, 0, len(issues))
+	for _, issue := range issues {
+		e := Error{
+			Pointer: issue.Path.JSONPointer(),
+			Code:    issue.Code.String(),
+			Detail:  issue.Message,
+		}
+
+		switch issue.Code {
+		case codec.CodeTypeMismatch:
+			e.Params = map[string]any{
+				"expected": issue.Expected,
+				"actual":   issue.Actual,
+			}
+		case codec.CodeUnknownField:
+			if len(issue.Allowed) > 0 {
+				e.Params = map[string]any{"allowed": issue.Allowed}
+			}
+		}
+
+		res = append(res, e)
+	}
+	return res
+}
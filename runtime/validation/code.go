@@ -0,0 +1,70 @@
+package validation
+
+import "fmt"
+
+// codeNames holds the stable names of codes. Names are part of the wire format and must never
+// change, unlike the numeric values of the codes.
+var codeNames = map[Code]string{
+	CodeIntMax:               "int.max",
+	CodeIntMin:               "int.min",
+	CodeIntExclMax:           "int.exclusiveMax",
+	CodeIntExclMin:           "int.exclusiveMin",
+	CodeIntMultipleOf:        "int.multipleOf",
+	CodeNumMax:               "num.max",
+	CodeNumMin:               "num.min",
+	CodeNumExclMax:           "num.exclusiveMax",
+	CodeNumExclMin:           "num.exclusiveMin",
+	CodeStrMinLen:            "str.minLength",
+	CodeStrMaxLen:            "str.maxLength",
+	CodeStrLen:               "str.length",
+	CodeStrPattern:           "str.pattern",
+	CodeStrFormat:            "str.format",
+	CodeArrMinItems:          "arr.minItems",
+	CodeArrMaxItems:          "arr.maxItems",
+	CodeArrLen:               "arr.length",
+	CodeArrUniqueItems:       "arr.uniqueItems",
+	CodeArrMinContains:       "arr.minContains",
+	CodeArrMaxContains:       "arr.maxContains",
+	CodeObjMinProps:          "obj.minProperties",
+	CodeObjMaxProps:          "obj.maxProperties",
+	CodeObjLen:               "obj.length",
+	CodeObjPropNotAllowed:    "obj.propertyNotAllowed",
+	CodeObjPropSchema:        "obj.propertySchema",
+	CodeObjDependentRequired: "obj.dependentRequired",
+	CodeObjDependentSchema:   "obj.dependentSchema",
+	CodeObjIfThen:            "obj.ifThen",
+	CodeObjIfElse:            "obj.ifElse",
+}
+
+var codesByName = func() map[string]Code {
+	res := make(map[string]Code, len(codeNames))
+	for code, name := range codeNames {
+		res[name] = code
+	}
+	return res
+}()
+
+// String returns the stable name of c, e.g. "str.pattern".
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", int(c))
+}
+
+func (c Code) MarshalText() ([]byte, error) {
+	name, ok := codeNames[c]
+	if !ok {
+		return nil, fmt.Errorf("validation: unknown code %d", int(c))
+	}
+	return []byte(name), nil
+}
+
+func (c *Code) UnmarshalText(text []byte) error {
+	code, ok := codesByName[string(text)]
+	if !ok {
+		return fmt.Errorf("validation: unknown code %q", text)
+	}
+	*c = code
+	return nil
+}
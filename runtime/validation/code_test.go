@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCodeText(t *testing.T) {
+	for c := CodeIntMax; c <= CodeArrMaxContains; c++ {
+		text, err := c.MarshalText()
+		if err != nil {
+			t.Fatalf("code %d: %v", int(c), err)
+		}
+		if string(text) != c.String() {
+			t.Errorf("code %d: got text %q and string %q", int(c), text, c.String())
+		}
+
+		var got Code
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if got != c {
+			t.Errorf("%s decoded as %d, want %d", text, int(got), int(c))
+		}
+	}
+
+	if _, err := Code(-1).MarshalText(); err == nil {
+		t.Error("unknown code was encoded")
+	}
+	if got := Code(1000).String(); got != "Code(1000)" {
+		t.Errorf("got %q for an unknown code", got)
+	}
+	for _, name := range []string{"", "int", "INT.MAX", "field.unknown"} {
+		var c Code
+		if err := c.UnmarshalText([]byte(name)); err == nil {
+			t.Errorf("unknown name %q decoded as %d", name, int(c))
+		}
+	}
+}
+
+func TestCodeJSON(t *testing.T) {
+	data, err := json.Marshal([]Code{CodeStrPattern, CodeObjIfElse})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `["str.pattern","obj.ifElse"]` {
+		t.Errorf("got %s", data)
+	}
+
+	var got []Code
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != CodeStrPattern || got[1] != CodeObjIfElse {
+		t.Errorf("got %v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`["str.regex"]`), &got); err == nil {
+		t.Error("unknown name was decoded")
+	}
+}
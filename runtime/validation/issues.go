@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/maketaio/openapi/runtime/codec"
+	"github.com/maketaio/openapi/runtime/fields"
+)
+
+// Issues is the list of issues found while validating a value. It implements error and exposes
+// each issue through Unwrap, so errors.As can extract a single *Issue.
+type Issues []*Issue
+
+func (is Issues) Error() string {
+	msgs := make([]string, 0, len(is))
+	for _, issue := range is {
+		msgs = append(msgs, issue.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (is Issues) Unwrap() []error {
+	errs := make([]error, 0, len(is))
+	for _, issue := range is {
+		errs = append(errs, issue)
+	}
+	return errs
+}
+
+// Err returns is as an error, or nil if there are no issues. Use it rather than converting is to
+// error directly, which gives a non-nil error for an empty list.
+func (is Issues) Err() error {
+	if len(is) == 0 {
+		return nil
+	}
+	return is
+}
+
+// Filter returns the issues for which keep returns true.
+func (is Issues) Filter(keep func(*Issue) bool) Issues {
+	var res Issues
+	for _, issue := range is {
+		if keep(issue) {
+			res = append(res, issue)
+		}
+	}
+	return res
+}
+
+// ByCode returns the issues with any of the given codes.
+func (is Issues) ByCode(codes ...Code) Issues {
+	return is.Filter(func(issue *Issue) bool {
+		return slices.Contains(codes, issue.Code)
+	})
+}
+
+// ByPath returns the issues at prefix or below it.
+func (is Issues) ByPath(prefix fields.Path) Issues {
+	return is.Filter(func(issue *Issue) bool {
+		return hasPrefix(issue.Path, prefix)
+	})
+}
+
+// Merge returns an error holding the codec issues found while decoding a value together with the
+// issues found while validating it, or nil if there are none.
+func Merge(decode []*codec.Issue, issues Issues) error {
+	if len(decode) == 0 && len(issues) == 0 {
+		return nil
+	}
+	return &Report{Decode: decode, Issues: issues}
+}
+
+// Report combines the codec and validation issues of a value. errors.As finds either kind of
+// issue through Unwrap.
+type Report struct {
+	Decode []*codec.Issue
+	Issues Issues
+}
+
+func (r *Report) Error() string {
+	msgs := make([]string, 0, len(r.Decode)+len(r.Issues))
+	for _, issue := range r.Decode {
+		msgs = append(msgs, issue.Error())
+	}
+	for _, issue := range r.Issues {
+		msgs = append(msgs, issue.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (r *Report) Unwrap() []error {
+	errs := make([]error, 0, len(r.Decode)+len(r.Issues))
+	for _, issue := range r.Decode {
+		errs = append(errs, issue)
+	}
+	return append(errs, r.Issues.Unwrap()...)
+}
+
+func hasPrefix(path, prefix fields.Path) bool {
+	return len(path) >= len(prefix) && slices.Equal(path[:len(prefix)], prefix)
+}
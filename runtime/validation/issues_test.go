@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/maketaio/openapi/runtime/codec"
+	"github.com/maketaio/openapi/runtime/fields"
+)
+
+var (
+	nameMax   = NewStrMaxLenIssue(fields.Path{}.Field("name"), 10)
+	itemMin   = NewIntMinIssue(fields.Path{}.Field("items").Index(0).Field("qty"), 1)
+	itemsMax  = NewArrMaxItemsIssue(fields.Path{}.Field("items"), 5)
+	itemsxMin = NewIntMinIssue(fields.Path{}.Field("itemsx"), 1)
+	allIssues = Issues{nameMax, itemMin, itemsMax, itemsxMin}
+)
+
+func TestIssuesFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Issues
+		want Issues
+	}{
+		{"filter", allIssues.Filter(func(i *Issue) bool { return len(i.Path) > 1 }), Issues{itemMin}},
+		{"filter none", allIssues.Filter(func(*Issue) bool { return false }), nil},
+		{"by code", allIssues.ByCode(CodeIntMin), Issues{itemMin, itemsxMin}},
+		{"by codes", allIssues.ByCode(CodeStrMaxLen, CodeArrMaxItems), Issues{nameMax, itemsMax}},
+		{"by no code", allIssues.ByCode(), nil},
+		{"by path", allIssues.ByPath(fields.Path{}.Field("items")), Issues{itemMin, itemsMax}},
+		{"by nested path", allIssues.ByPath(fields.Path{}.Field("items").Index(0)), Issues{itemMin}},
+		{"by index versus property", allIssues.ByPath(fields.Path{}.Field("items").Field("0")), nil},
+		{"by empty path", allIssues.ByPath(nil), allIssues},
+		{"by longer path", allIssues.ByPath(fields.Path{}.Field("name").Field("first")), nil},
+		{"empty", Issues(nil).ByCode(CodeIntMin), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !slices.Equal(test.got, test.want) {
+				t.Errorf("got %v, want %v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestIssuesErr(t *testing.T) {
+	if err := Issues(nil).Err(); err != nil {
+		t.Errorf("got %v for no issues", err)
+	}
+	if err := (Issues{}).Err(); err != nil {
+		t.Errorf("got %v for an empty list", err)
+	}
+
+	err := allIssues.Err()
+	var issue *Issue
+	if !errors.As(err, &issue) || issue != nameMax {
+		t.Errorf("errors.As found %v, want the first issue", issue)
+	}
+	if !errors.Is(err, itemsMax) {
+		t.Error("errors.Is did not find an issue")
+	}
+	if want := "name must be at most 10 characters; items[0].qty must be greater than or equal to 1; items must have at most 5 items; itemsx must be greater than or equal to 1"; err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	unknown := &codec.Issue{Path: fields.Path{}.Field("x"), Code: codec.CodeUnknownField, Message: "x is not allowed"}
+
+	tests := []struct {
+		name   string
+		decode []*codec.Issue
+		issues Issues
+		msg    string
+	}{
+		{"nothing", nil, nil, ""},
+		{"empty", []*codec.Issue{}, Issues{}, ""},
+		{"decode only", []*codec.Issue{unknown}, nil, "x is not allowed"},
+		{"validation only", nil, Issues{nameMax}, "name must be at most 10 characters"},
+		{"both", []*codec.Issue{unknown}, Issues{nameMax}, "x is not allowed; name must be at most 10 characters"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Merge(test.decode, test.issues)
+			if test.msg == "" {
+				if err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.msg {
+				t.Fatalf("got %v, want %q", err, test.msg)
+			}
+
+			var report *Report
+			if !errors.As(err, &report) {
+				t.Fatal("errors.As did not find the report")
+			}
+
+			var decodeIssue *codec.Issue
+			if got := errors.As(err, &decodeIssue); got != (len(test.decode) > 0) {
+				t.Errorf("errors.As found a codec issue: %v", got)
+			}
+			var issue *Issue
+			if got := errors.As(err, &issue); got != (len(test.issues) > 0) {
+				t.Errorf("errors.As found a validation issue: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package validation
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// MatchString reports whether s matches pattern. Compiled patterns are cached, and a pattern that
+// does not compile never matches.
+func MatchString(pattern, s string) bool {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(s)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+
+	patterns.Store(pattern, re)
+	return re.MatchString(s)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// MatchFormat reports whether s is valid for the given string format. Formats that are not
+// recognized always match.
+func MatchFormat(format, s string) bool {
+	switch format {
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil
+	case "ipv6":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() == nil
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	}
+
+	return true
+}
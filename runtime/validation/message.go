@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"encoding/json"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// messages is the catalog issue messages are rendered from. Languages without a message for a code
+// fall back to English, see Localize.
+var messages = catalog.NewBuilder(catalog.Fallback(language.English))
+
+// keyPropSchemaAdditional is the catalog key used for CodeObjPropSchema when the value was checked
+// against additionalProperties rather than a pattern.
+const keyPropSchemaAdditional = "obj.propertySchema.additional"
+
+// englishMessages holds the default messages keyed by code name. Each message receives the path
+// of the issue followed by the params listed next to it.
+var englishMessages = map[string]string{
+	CodeIntMax.String():               "%[1]s must be less than or equal to %[2]d",                      // IntMax
+	CodeIntMin.String():               "%[1]s must be greater than or equal to %[2]d",                   // IntMin
+	CodeIntExclMax.String():           "%[1]s must be less than %[2]d",                                  // IntExclMax
+	CodeIntExclMin.String():           "%[1]s must be greater than %[2]d",                               // IntExclMin
+	CodeIntMultipleOf.String():        "%[1]s must be a multiple of %[2]d",                              // IntMultipleOf
+	CodeNumMax.String():               "%[1]s must be less than or equal to %[2]f",                      // NumMax
+	CodeNumMin.String():               "%[1]s must be greater than or equal to %[2]f",                   // NumMin
+	CodeNumExclMax.String():           "%[1]s must be less than %[2]f",                                  // NumExclMax
+	CodeNumExclMin.String():           "%[1]s must be greater than %[2]f",                               // NumExclMin
+	CodeStrMinLen.String():            "%[1]s must be at least %[2]d characters",                        // StrMinLen
+	CodeStrMaxLen.String():            "%[1]s must be at most %[2]d characters",                         // StrMaxLen
+	CodeStrLen.String():               "%[1]s must have %[2]d characters",                               // StrLen
+	CodeStrPattern.String():           "%[1]s must match pattern %[2]s",                                 // StrPattern
+	CodeStrFormat.String():            "%[1]s must be a valid %[2]s",                                    // StrFormat
+	CodeArrMinItems.String():          "%[1]s must have at least %[2]d items",                           // ArrMinItems
+	CodeArrMaxItems.String():          "%[1]s must have at most %[2]d items",                            // ArrMaxItems
+	CodeArrLen.String():               "%[1]s must have %[2]d items",                                    // ArrLen
+	CodeArrUniqueItems.String():       "%[1]s must have unique items, item %[3]d duplicates item %[2]d", // first, duplicate index
+	CodeArrMinContains.String():       "%[1]s must have at least %[2]d matching items, but has %[3]d",   // ArrMinContains, ArrContains
+	CodeArrMaxContains.String():       "%[1]s must have at most %[2]d matching items, but has %[3]d",    // ArrMaxContains, ArrContains
+	CodeObjMinProps.String():          "%[1]s must have at least %[2]d properties",                      // ObjMinProps
+	CodeObjMaxProps.String():          "%[1]s must have at most %[2]d properties",                       // ObjMaxProps
+	CodeObjLen.String():               "%[1]s must have %[2]d properties",                               // ObjLen
+	CodeObjPropNotAllowed.String():    "%[1]s is not an allowed property",
+	CodeObjPropSchema.String():        "%[1]s does not match the schema for properties matching %[2]s", // ObjPropPattern
+	keyPropSchemaAdditional:           "%[1]s does not match the schema for additional properties",
+	CodeObjDependentRequired.String(): "%[1]s is required when %[2]s is present",               // ObjDependency
+	CodeObjDependentSchema.String():   "%[1]s is missing or not allowed when %[2]s is present", // ObjDependency
+	CodeObjIfThen.String():            "%[1]s is missing or not allowed when the if condition holds",
+	CodeObjIfElse.String():            "%[1]s is missing or not allowed when the if condition does not hold",
+}
+
+func init() {
+	for key, msg := range englishMessages {
+		if err := messages.SetString(language.English, key, msg); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// SetMessage registers format as the message for code in the language tag, overriding the default
+// message or adding a new language. format receives the same arguments as the English message:
+// the path of the issue followed by the params relevant to code.
+func SetMessage(tag language.Tag, code Code, format string) error {
+	return messages.SetString(tag, code.String(), format)
+}
+
+// Localize renders the message of i in the language that best matches tag. If Message is set, it
+// is returned as is.
+func (i *Issue) Localize(tag language.Tag) string {
+	if i.Message != "" {
+		return i.Message
+	}
+
+	key, args := i.messageArgs()
+	msg := message.NewPrinter(tag, message.Catalog(messages)).Sprintf(key, args...)
+	if msg != key {
+		return msg
+	}
+
+	// The printer falls back to the key itself when the language has no message for it. Every
+	// message mentions the path, so it can never be equal to the key.
+	return message.NewPrinter(language.English, message.Catalog(messages)).Sprintf(key, args...)
+}
+
+// messageArgs returns the catalog key and the arguments for the message of i.
+func (i *Issue) messageArgs() (string, []any) {
+	p := i.Params
+	args := []any{i.Path}
+
+	switch i.Code {
+	case CodeIntMax:
+		args = append(args, deref(p.IntMax))
+	case CodeIntMin:
+		args = append(args, deref(p.IntMin))
+	case CodeIntExclMax:
+		args = append(args, deref(p.IntExclMax))
+	case CodeIntExclMin:
+		args = append(args, deref(p.IntExclMin))
+	case CodeIntMultipleOf:
+		args = append(args, deref(p.IntMultipleOf))
+	case CodeNumMax:
+		args = append(args, deref(p.NumMax))
+	case CodeNumMin:
+		args = append(args, deref(p.NumMin))
+	case CodeNumExclMax:
+		args = append(args, deref(p.NumExclMax))
+	case CodeNumExclMin:
+		args = append(args, deref(p.NumExclMin))
+	case CodeStrMinLen:
+		args = append(args, deref(p.StrMinLen))
+	case CodeStrMaxLen:
+		args = append(args, deref(p.StrMaxLen))
+	case CodeStrLen:
+		args = append(args, deref(p.StrLen))
+	case CodeStrPattern:
+		args = append(args, p.StrPattern)
+	case CodeStrFormat:
+		args = append(args, p.StrFormat)
+	case CodeArrMinItems:
+		args = append(args, deref(p.ArrMinItems))
+	case CodeArrMaxItems:
+		args = append(args, deref(p.ArrMaxItems))
+	case CodeArrLen:
+		args = append(args, deref(p.ArrLen))
+	case CodeArrUniqueItems:
+		first, dup := 0, 0
+		if len(p.ArrDuplicates) == 2 {
+			first, dup = p.ArrDuplicates[0], p.ArrDuplicates[1]
+		}
+		args = append(args, first, dup)
+	case CodeArrMinContains:
+		args = append(args, deref(p.ArrMinContains), deref(p.ArrContains))
+	case CodeArrMaxContains:
+		args = append(args, deref(p.ArrMaxContains), deref(p.ArrContains))
+	case CodeObjMinProps:
+		args = append(args, deref(p.ObjMinProps))
+	case CodeObjMaxProps:
+		args = append(args, deref(p.ObjMaxProps))
+	case CodeObjLen:
+		args = append(args, deref(p.ObjLen))
+	case CodeObjPropSchema:
+		if p.ObjPropPattern == "" {
+			return keyPropSchemaAdditional, args
+		}
+		args = append(args, p.ObjPropPattern)
+	case CodeObjDependentRequired, CodeObjDependentSchema:
+		args = append(args, p.ObjDependency)
+	}
+
+	return i.Code.String(), args
+}
+
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
+
+// MarshalJSON encodes i with its message rendered in English.
+func (i Issue) MarshalJSON() ([]byte, error) {
+	type issue Issue
+	v := issue(i)
+	v.Message = i.Localize(language.English)
+	return json.Marshal(v)
+}
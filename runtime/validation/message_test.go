@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maketaio/openapi/runtime/fields"
+	"golang.org/x/text/language"
+)
+
+func TestEnglishMessages(t *testing.T) {
+	path := fields.Path{}.Field("items").Index(2)
+
+	tests := []struct {
+		issue *Issue
+		want  string
+	}{
+		{NewIntMaxIssue(path, 5), "items[2] must be less than or equal to 5"},
+		{NewIntExclMinIssue(path, 0), "items[2] must be greater than 0"},
+		{NewNumMaxIssue(path, 1.5), "items[2] must be less than or equal to 1.500000"},
+		{NewStrPatternIssue(path, "^[a-z]+$"), "items[2] must match pattern ^[a-z]+$"},
+		{NewStrFormatIssue(path, "email"), "items[2] must be a valid email"},
+		{NewArrUniqueItemsIssue(path, 0, 3), "items[2] must have unique items, item 3 duplicates item 0"},
+		{NewArrMinContainsIssue(path, 2, 1), "items[2] must have at least 2 matching items, but has 1"},
+		{NewObjPropNotAllowedIssue(path), "items[2] is not an allowed property"},
+		{NewObjPropSchemaIssue(path, "^x-"), "items[2] does not match the schema for properties matching ^x-"},
+		{NewObjPropSchemaIssue(path, ""), "items[2] does not match the schema for additional properties"},
+		{NewObjDependentRequiredIssue(path, "a"), "items[2] is required when a is present"},
+		{NewObjIfElseIssue(path), "items[2] is missing or not allowed when the if condition does not hold"},
+		{&Issue{Path: path, Code: CodeIntMax, Message: "custom"}, "custom"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.issue.Error(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEveryCodeHasAMessage(t *testing.T) {
+	for c := CodeIntMax; c <= CodeArrMaxContains; c++ {
+		msg := (&Issue{Path: fields.Path{}.Field("x"), Code: c}).Localize(language.English)
+		if !strings.HasPrefix(msg, "x ") || strings.Contains(msg, "%!") {
+			t.Errorf("code %s has message %q", c, msg)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	// The catalog is shared, so the test registers languages no other test uses
+	if err := SetMessage(language.German, CodeIntMax, "%[1]s darf höchstens %[2]d sein"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetMessage(language.BritishEnglish, CodeStrFormat, "%[1]s must be a valid %[2]s, mate"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := fields.Path{}.Field("n")
+	tests := []struct {
+		name  string
+		tag   language.Tag
+		issue *Issue
+		want  string
+	}{
+		{"translated", language.German, NewIntMaxIssue(path, 3), "n darf höchstens 3 sein"},
+		{"regional match", language.MustParse("de-CH"), NewIntMaxIssue(path, 3), "n darf höchstens 3 sein"},
+		{"missing translation falls back to English", language.German, NewIntMinIssue(path, 3), "n must be greater than or equal to 3"},
+		{"unknown language falls back to English", language.Japanese, NewIntMaxIssue(path, 3), "n must be less than or equal to 3"},
+		{"override", language.BritishEnglish, NewStrFormatIssue(path, "uuid"), "n must be a valid uuid, mate"},
+		{"override leaves English alone", language.English, NewStrFormatIssue(path, "uuid"), "n must be a valid uuid"},
+		{"explicit message", language.German, &Issue{Path: path, Code: CodeIntMax, Message: "fixed"}, "fixed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.issue.Localize(test.tag); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetMessageEnglish(t *testing.T) {
+	key := CodeObjLen.String()
+	t.Cleanup(func() {
+		if err := SetMessage(language.English, CodeObjLen, englishMessages[key]); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if err := SetMessage(language.English, CodeObjLen, "%[1]s needs exactly %[2]d keys"); err != nil {
+		t.Fatal(err)
+	}
+	if got := NewObjLenIssue(fields.Path{}.Field("m"), 2).Error(); got != "m needs exactly 2 keys" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Duplicate holds the indexes of an item and of the earlier item it is equal to.
+type Duplicate struct {
+	First int
+	Dup   int
+}
+
+// FindDuplicates returns every item of items that is equal to an earlier item, in index order.
+func FindDuplicates[T comparable](items []T) []Duplicate {
+	var dups []Duplicate
+
+	seen := make(map[T]int, len(items))
+	for i, item := range items {
+		if first, ok := seen[item]; ok {
+			dups = append(dups, Duplicate{First: first, Dup: i})
+			continue
+		}
+
+		seen[item] = i
+	}
+
+	return dups
+}
+
+// FindDuplicatesJSON is like FindDuplicates but compares items by their canonical JSON encoding,
+// which makes it usable for non-comparable item types such as maps, slices and structs holding
+// pointers. Items that cannot be encoded are never reported as duplicates.
+func FindDuplicatesJSON[T any](items []T) []Duplicate {
+	var dups []Duplicate
+
+	seen := make(map[string]int, len(items))
+	for i, item := range items {
+		key, err := canonicalJSON(item)
+		if err != nil {
+			continue
+		}
+
+		if first, ok := seen[key]; ok {
+			dups = append(dups, Duplicate{First: first, Dup: i})
+			continue
+		}
+
+		seen[key] = i
+	}
+
+	return dups
+}
+
+// canonicalJSON encodes v so that semantically equal JSON values produce the same string:
+// insignificant whitespace is dropped and object keys are sorted.
+func canonicalJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return "", err
+	}
+
+	data, err = json.Marshal(generic)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
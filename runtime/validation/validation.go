@@ -1,13 +1,14 @@
 package validation
 
 import (
-	"fmt"
-
 	"github.com/maketaio/openapi/runtime/fields"
+	"golang.org/x/text/language"
 )
 
 type Code int
 
+// Codes are numbered in the order they were added. New codes must be appended so that the values of
+// existing ones never change.
 const (
 	CodeIntMax Code = iota
 	CodeIntMin
@@ -28,39 +29,71 @@ const (
 	CodeObjMinProps
 	CodeObjMaxProps
 	CodeObjLen
+	CodeArrUniqueItems
+	CodeStrFormat
+	CodeObjPropNotAllowed
+	CodeObjPropSchema
+	CodeObjDependentRequired
+	CodeObjDependentSchema
+	CodeObjIfThen
+	CodeObjIfElse
+	CodeArrMinContains
+	CodeArrMaxContains
 )
 
 type Issue struct {
-	Path    fields.Path
-	Code    Code
-	Params  Params
-	Message string
+	Path   fields.Path `json:"path"`
+	Code   Code        `json:"code"`
+	Params Params      `json:"params,omitzero"`
+	// Message overrides the message rendered from the catalog when set. See Localize.
+	Message string `json:"message"`
 }
 
+// Error returns the message of the issue in English.
 func (i *Issue) Error() string {
-	return i.Message
+	return i.Localize(language.English)
 }
 
+// Params holds the parameters of an issue. Only the parameters relevant to the issue's code are
+// set; unset parameters are omitted from JSON.
 type Params struct {
-	IntMax        *int64
-	IntMin        *int64
-	IntExclMax    *int64
-	IntExclMin    *int64
-	IntMultipleOf *int64
-	NumMax        *float64
-	NumMin        *float64
-	NumExclMax    *float64
-	NumExclMin    *float64
-	StrMinLen     *int64
-	StrMaxLen     *int64
-	StrLen        *int64
-	StrPattern    string
-	ArrMinItems   *int64
-	ArrMaxItems   *int64
-	ArrLen        *int64
-	ObjMinProps   *int64
-	ObjMaxProps   *int64
-	ObjLen        *int64
+	IntMax         *int64   `json:"intMax,omitempty"`
+	IntMin         *int64   `json:"intMin,omitempty"`
+	IntExclMax     *int64   `json:"intExclMax,omitempty"`
+	IntExclMin     *int64   `json:"intExclMin,omitempty"`
+	IntMultipleOf  *int64   `json:"intMultipleOf,omitempty"`
+	NumMax         *float64 `json:"numMax,omitempty"`
+	NumMin         *float64 `json:"numMin,omitempty"`
+	NumExclMax     *float64 `json:"numExclMax,omitempty"`
+	NumExclMin     *float64 `json:"numExclMin,omitempty"`
+	StrMinLen      *int64   `json:"strMinLen,omitempty"`
+	StrMaxLen      *int64   `json:"strMaxLen,omitempty"`
+	StrLen         *int64   `json:"strLen,omitempty"`
+	StrPattern     string   `json:"strPattern,omitempty"`
+	StrFormat      string   `json:"strFormat,omitempty"`
+	ArrMinItems    *int64   `json:"arrMinItems,omitempty"`
+	ArrMaxItems    *int64   `json:"arrMaxItems,omitempty"`
+	ArrLen         *int64   `json:"arrLen,omitempty"`
+	ArrDuplicates  []int    `json:"arrDuplicates,omitempty"`
+	ArrMinContains *int64   `json:"arrMinContains,omitempty"`
+	ArrMaxContains *int64   `json:"arrMaxContains,omitempty"`
+	ArrContains    *int64   `json:"arrContains,omitempty"`
+	ObjMinProps    *int64   `json:"objMinProps,omitempty"`
+	ObjMaxProps    *int64   `json:"objMaxProps,omitempty"`
+	ObjLen         *int64   `json:"objLen,omitempty"`
+	ObjPropPattern string   `json:"objPropPattern,omitempty"`
+	ObjDependency  string   `json:"objDependency,omitempty"`
+}
+
+// IsZero reports whether no parameter is set.
+func (p Params) IsZero() bool {
+	return p.IntMax == nil && p.IntMin == nil && p.IntExclMax == nil && p.IntExclMin == nil &&
+		p.IntMultipleOf == nil && p.NumMax == nil && p.NumMin == nil && p.NumExclMax == nil &&
+		p.NumExclMin == nil && p.StrMinLen == nil && p.StrMaxLen == nil && p.StrLen == nil &&
+		p.StrPattern == "" && p.StrFormat == "" && p.ArrMinItems == nil && p.ArrMaxItems == nil &&
+		p.ArrLen == nil && p.ArrDuplicates == nil && p.ArrMinContains == nil && p.ArrMaxContains == nil &&
+		p.ArrContains == nil && p.ObjMinProps == nil && p.ObjMaxProps == nil && p.ObjLen == nil &&
+		p.ObjPropPattern == "" && p.ObjDependency == ""
 }
 
 func NewIntMaxIssue(path fields.Path, max int64) *Issue {
@@ -70,7 +103,6 @@ func NewIntMaxIssue(path fields.Path, max int64) *Issue {
 		Params: Params{
 			IntMax: &max,
 		},
-		Message: fmt.Sprintf("%s must be less than or equal to %d", path, max),
 	}
 }
 
@@ -81,7 +113,6 @@ func NewIntMinIssue(path fields.Path, min int64) *Issue {
 		Params: Params{
 			IntMin: &min,
 		},
-		Message: fmt.Sprintf("%s must be greater than or equal to %d", path, min),
 	}
 }
 
@@ -92,7 +123,6 @@ func NewIntExclMaxIssue(path fields.Path, max int64) *Issue {
 		Params: Params{
 			IntExclMax: &max,
 		},
-		Message: fmt.Sprintf("%s must be less than %d", path, max),
 	}
 }
 
@@ -103,7 +133,6 @@ func NewIntExclMinIssue(path fields.Path, min int64) *Issue {
 		Params: Params{
 			IntExclMin: &min,
 		},
-		Message: fmt.Sprintf("%s must be greater than %d", path, min),
 	}
 }
 
@@ -114,7 +143,6 @@ func NewNumMaxIssue(path fields.Path, max float64) *Issue {
 		Params: Params{
 			NumMax: &max,
 		},
-		Message: fmt.Sprintf("%s must be less than or equal to %f", path, max),
 	}
 }
 
@@ -125,7 +153,6 @@ func NewNumMinIssue(path fields.Path, min float64) *Issue {
 		Params: Params{
 			NumMin: &min,
 		},
-		Message: fmt.Sprintf("%s must be greater than or equal to %f", path, min),
 	}
 }
 
@@ -136,7 +163,6 @@ func NewNumExclMaxIssue(path fields.Path, max float64) *Issue {
 		Params: Params{
 			NumExclMax: &max,
 		},
-		Message: fmt.Sprintf("%s must be less than %f", path, max),
 	}
 }
 
@@ -147,7 +173,6 @@ func NewNumExclMinIssue(path fields.Path, min float64) *Issue {
 		Params: Params{
 			NumExclMin: &min,
 		},
-		Message: fmt.Sprintf("%s must be greater than %f", path, min),
 	}
 }
 
@@ -158,7 +183,6 @@ func NewStrMinLenIssue(path fields.Path, min int64) *Issue {
 		Params: Params{
 			StrMinLen: &min,
 		},
-		Message: fmt.Sprintf("%s must be at least %d characters", path, min),
 	}
 }
 
@@ -169,7 +193,6 @@ func NewStrMaxLenIssue(path fields.Path, max int64) *Issue {
 		Params: Params{
 			StrMaxLen: &max,
 		},
-		Message: fmt.Sprintf("%s must be at most %d characters", path, max),
 	}
 }
 
@@ -180,7 +203,6 @@ func NewStrLenIssue(path fields.Path, len int64) *Issue {
 		Params: Params{
 			StrLen: &len,
 		},
-		Message: fmt.Sprintf("%s must have %d characters", path, len),
 	}
 }
 
@@ -191,7 +213,16 @@ func NewStrPatternIssue(path fields.Path, pattern string) *Issue {
 		Params: Params{
 			StrPattern: pattern,
 		},
-		Message: fmt.Sprintf("%s must match pattern %s", path, pattern),
+	}
+}
+
+func NewStrFormatIssue(path fields.Path, format string) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeStrFormat,
+		Params: Params{
+			StrFormat: format,
+		},
 	}
 }
 
@@ -202,7 +233,6 @@ func NewArrMinItemsIssue(path fields.Path, min int64) *Issue {
 		Params: Params{
 			ArrMinItems: &min,
 		},
-		Message: fmt.Sprintf("%s must have at least %d items", path, min),
 	}
 }
 
@@ -213,7 +243,6 @@ func NewArrMaxItemsIssue(path fields.Path, max int64) *Issue {
 		Params: Params{
 			ArrMaxItems: &max,
 		},
-		Message: fmt.Sprintf("%s must have at most %d items", path, max),
 	}
 }
 
@@ -224,7 +253,40 @@ func NewArrLenIssue(path fields.Path, len int64) *Issue {
 		Params: Params{
 			ArrLen: &len,
 		},
-		Message: fmt.Sprintf("%s must have %d items", path, len),
+	}
+}
+
+func NewArrUniqueItemsIssue(path fields.Path, first, dup int) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeArrUniqueItems,
+		Params: Params{
+			ArrDuplicates: []int{first, dup},
+		},
+	}
+}
+
+// NewArrMinContainsIssue reports an array where fewer than min items match the contains schema.
+func NewArrMinContainsIssue(path fields.Path, min, actual int64) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeArrMinContains,
+		Params: Params{
+			ArrMinContains: &min,
+			ArrContains:    &actual,
+		},
+	}
+}
+
+// NewArrMaxContainsIssue reports an array where more than max items match the contains schema.
+func NewArrMaxContainsIssue(path fields.Path, max, actual int64) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeArrMaxContains,
+		Params: Params{
+			ArrMaxContains: &max,
+			ArrContains:    &actual,
+		},
 	}
 }
 
@@ -235,7 +297,6 @@ func NewObjMinPropsIssue(path fields.Path, min int64) *Issue {
 		Params: Params{
 			ObjMinProps: &min,
 		},
-		Message: fmt.Sprintf("%s must have at least %d properties", path, min),
 	}
 }
 
@@ -246,7 +307,6 @@ func NewObjMaxPropsIssue(path fields.Path, max int64) *Issue {
 		Params: Params{
 			ObjMaxProps: &max,
 		},
-		Message: fmt.Sprintf("%s must have at most %d properties", path, max),
 	}
 }
 
@@ -257,6 +317,62 @@ func NewObjLenIssue(path fields.Path, len int64) *Issue {
 		Params: Params{
 			ObjLen: &len,
 		},
-		Message: fmt.Sprintf("%s must have %d properties", path, len),
+	}
+}
+
+func NewObjPropNotAllowedIssue(path fields.Path) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeObjPropNotAllowed,
+	}
+}
+
+// NewObjPropSchemaIssue reports a property value that does not match the schema selected by its
+// name. An empty pattern means the value was checked against additionalProperties.
+func NewObjPropSchemaIssue(path fields.Path, pattern string) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeObjPropSchema,
+		Params: Params{
+			ObjPropPattern: pattern,
+		},
+	}
+}
+
+// NewObjDependentRequiredIssue reports a property at path that is required because the dependency
+// property is present.
+func NewObjDependentRequiredIssue(path fields.Path, dependency string) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeObjDependentRequired,
+		Params: Params{
+			ObjDependency: dependency,
+		},
+	}
+}
+
+// NewObjDependentSchemaIssue reports a property at path that violates the schema applied because
+// the dependency property is present.
+func NewObjDependentSchemaIssue(path fields.Path, dependency string) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeObjDependentSchema,
+		Params: Params{
+			ObjDependency: dependency,
+		},
+	}
+}
+
+func NewObjIfThenIssue(path fields.Path) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeObjIfThen,
+	}
+}
+
+func NewObjIfElseIssue(path fields.Path) *Issue {
+	return &Issue{
+		Path: path,
+		Code: CodeObjIfElse,
 	}
 }